@@ -2,7 +2,7 @@ package main
 
 import (
 	"fmt"
-	"main/humanizetime"
+	"main/boardtext"
 	"main/minesweeper"
 	"strconv"
 	"strings"
@@ -65,8 +65,7 @@ var CommandHandlers = map[string]func(s *discordgo.Session, i *discordgo.Interac
 			endTS   = int64(endID) >> int64(22)
 		)
 
-		content = fmt.Sprintf(
-			"Heartbeat Latency: `%d`ms\nHTTP Latency:`%d`ms",
+		content = tr(interactionLocale(i), "ping.latency",
 			s.HeartbeatLatency().Milliseconds(),
 			endTS-startTS,
 		)
@@ -88,9 +87,35 @@ var CommandHandlers = map[string]func(s *discordgo.Session, i *discordgo.Interac
 		// Convert options to map.
 		optionMap := mapOptions(i.ApplicationCommandData().Options)
 		userID, isGuild := getUserID(i)
+		locale := interactionLocale(i)
+
+		if !gameChannelAllowed(s, i) {
+			return
+		}
+
+		// Fall back to the default difficulty of the user.
+		difficulty := getUserSettings(userID).DefaultDifficulty
+		if option, ok := optionMap["difficulty"]; ok {
+			difficulty = option.StringValue()
+		}
+		if !isInArray(difficulty, difficultyOrder) {
+			difficulty = "easy"
+		}
+
+		// Spoiler boards are played without the bot, so they are not tracked as games.
+		if style, ok := optionMap["style"]; ok && style.StringValue() == boardtext.StyleSpoiler {
+			board := minesweeper.NewGame(difficultyFromString(difficulty), 0, false, false)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: tr(locale, "game.board", board.TotalBombs) + "\n" + boardtext.Spoiler(board),
+				},
+			})
+			return
+		}
 
 		// Check if the user already has a game open.
-		if game, ok := Games[userID]; ok {
+		if game, ok := getGame(userID); ok {
 			var (
 				location  = i.GuildID
 				channel   = i.ChannelID
@@ -101,7 +126,7 @@ var CommandHandlers = map[string]func(s *discordgo.Session, i *discordgo.Interac
 				channel = s.State.User.ID
 			}
 
-			replyContent := fmt.Sprintf("You already have a game open at https://discord.com/channels/%s/%s/%s", location, channel, messageID)
+			replyContent := tr(locale, "game.alreadyOpen", location, channel, messageID)
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
@@ -130,7 +155,7 @@ var CommandHandlers = map[string]func(s *discordgo.Session, i *discordgo.Interac
 
 		// Create a new game based on the selected difficulty.
 		var Game *minesweeper.Game
-		switch optionMap["difficulty"].Value {
+		switch difficulty {
 		case "easy":
 			Game = minesweeper.NewGame(minesweeper.Easy, 0, false, false)
 		case "medium":
@@ -139,7 +164,7 @@ var CommandHandlers = map[string]func(s *discordgo.Session, i *discordgo.Interac
 			Game = minesweeper.NewGame(minesweeper.Hard, 0, false, false)
 		}
 
-		StartGame(s, i, Game, fmt.Sprintf("%v", optionMap["difficulty"].Value), userID)
+		StartGame(s, i, Game, difficulty, userID)
 	},
 	"custom": func(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		defer func() {
@@ -155,20 +180,147 @@ var CommandHandlers = map[string]func(s *discordgo.Session, i *discordgo.Interac
 		allowSurroundingBombs := optionMap["surroundingbombs"].BoolValue()
 		noStartSpot := optionMap["nostartspot"].BoolValue()
 
+		if !gameChannelAllowed(s, i) {
+			return
+		}
+
 		// Respond with a deferred message update initially.
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
 		})
 
-		if bombs <= 0 {
-			bombs = 1
+		startCustomGame(s, i, clampBombs(bombs), allowSurroundingBombs, noStartSpot, userID)
+	},
+	"preset": func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		defer func() {
+			if err := recover(); err != nil {
+				handlePanic(err)
+			}
+		}()
+		subcommand := i.ApplicationCommandData().Options[0]
+		optionMap := mapOptions(subcommand.Options)
+		userID, isGuild := getUserID(i)
+		locale := interactionLocale(i)
+
+		if subcommand.Name == "list" {
+			presets := getPresets()
+			embed := &discordgo.MessageEmbed{
+				Type:  discordgo.EmbedTypeRich,
+				Title: tr(locale, "preset.listTitle"),
+				Color: randomEmbedColor(),
+			}
+			for _, preset := range presets {
+				embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+					Name:  preset.Name,
+					Value: preset.settingsString(locale),
+				})
+			}
+			if len(presets) == 0 {
+				embed.Description = tr(locale, "preset.listEmpty")
+			}
+
+			if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Embeds: []*discordgo.MessageEmbed{embed},
+				},
+			}); err != nil {
+				cmdError(s, i, err)
+			}
+			return
+		}
+
+		preset, ok := getPreset(optionMap["name"].StringValue())
+		if !ok {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Flags:   1 << 6,
+					Content: tr(locale, "preset.unknown"),
+				},
+			})
+			return
+		}
+
+		if subcommand.Name == "play" && !gameChannelAllowed(s, i) {
+			return
 		}
-		if bombs >= 24 {
-			bombs = 24
+
+		config := getGuildConfig(i.GuildID)
+		var flags discordgo.MessageFlags
+		if subcommand.Name == "leaderboard" && config.LeaderboardVisibility == LeaderboardPrivate {
+			flags = 1 << 6
 		}
 
-		Game := minesweeper.NewGame(minesweeper.Custom, int(bombs), allowSurroundingBombs, noStartSpot)
-		StartGame(s, i, Game, "custom", userID)
+		// Respond with a deferred message update initially.
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Flags: flags,
+			},
+		})
+
+		switch subcommand.Name {
+		case "play":
+			if game, ok := getGame(userID); ok {
+				location := i.GuildID
+				if !isGuild {
+					location = "@me"
+				}
+				content := tr(locale, "game.alreadyOpen", location, game.ChannelID, game.BoardID)
+				s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+					Content: &content,
+				})
+				return
+			}
+
+			startCustomGame(s, i, preset.Bombs, preset.SurroundingBombs, preset.NoStartSpot, userID)
+		case "leaderboard":
+			targetGuild := "global"
+			if isGuild {
+				targetGuild = i.GuildID
+			}
+			if v, ok := optionMap["global"]; ok && v.BoolValue() {
+				targetGuild = "global"
+			}
+			if targetGuild != "global" && config.LeaderboardVisibility == LeaderboardHidden {
+				content := tr(locale, "leaderboard.hidden")
+				s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+					Content: &content,
+				})
+				return
+			}
+
+			var period string
+			if v, ok := optionMap["period"]; ok {
+				period = v.StringValue()
+			}
+
+			key, err := periodKey(period, time.Now())
+			if err != nil {
+				cmdError(s, i, err)
+				return
+			}
+
+			embed, components, err := generateLeaderboardEmbed(locale, leaderboardGuildName(locale, targetGuild), leaderboardQuery{
+				GuildID:    targetGuild,
+				Difficulty: "custom",
+				Metric:     MetricTime,
+				Preset:     preset.Key,
+				Period:     key,
+			})
+			if err != nil {
+				cmdError(s, i, err)
+				return
+			}
+
+			if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Embeds:     &[]*discordgo.MessageEmbed{&embed},
+				Components: &components,
+			}); err != nil {
+				cmdError(s, i, err)
+			}
+		}
 	},
 	"leaderboard": func(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		defer func() {
@@ -176,13 +328,23 @@ var CommandHandlers = map[string]func(s *discordgo.Session, i *discordgo.Interac
 				handlePanic(err)
 			}
 		}()
+		config := getGuildConfig(i.GuildID)
+		locale := interactionLocale(i)
+		var flags discordgo.MessageFlags
+		if config.LeaderboardVisibility == LeaderboardPrivate {
+			flags = 1 << 6
+		}
+
 		// Respond with a deferred message update initially.
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Flags: flags,
+			},
 		})
 
 		optionMap := mapOptions(i.ApplicationCommandData().Options)
-		_, isGuild := getUserID(i)
+		userID, isGuild := getUserID(i)
 
 		targetGuild := "global"
 		guildName := targetGuild
@@ -194,22 +356,82 @@ var CommandHandlers = map[string]func(s *discordgo.Session, i *discordgo.Interac
 				cmdError(s, i, err)
 				return
 			}
-			guildName = fmt.Sprintf("%s's", guild.Name)
+			guildName = tr(locale, "leaderboard.guildName", guild.Name)
 		}
 
-		if v, ok := optionMap["global"]; ok && v.BoolValue() {
+		var scope string
+		if v, ok := optionMap["scope"]; ok {
+			scope = v.StringValue()
+		}
+		if v, ok := optionMap["global"]; (ok && v.BoolValue()) || scope == "global" {
 			targetGuild = "global"
 			guildName = targetGuild
 		}
+		// Friend leaderboards work the same in every guild and in direct messages.
+		friends := scope == "friends"
+		if friends {
+			targetGuild = friendsScope(userID)
+			guildName = leaderboardGuildName(locale, targetGuild)
+		}
+		if !friends && targetGuild != "global" && config.LeaderboardVisibility == LeaderboardHidden {
+			content := tr(locale, "leaderboard.hidden")
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: &content,
+			})
+			return
+		}
+
+		var period string
+		if v, ok := optionMap["period"]; ok {
+			period = v.StringValue()
+		}
+		// A season picked by name can be a past one, which overrides the period.
+		var season string
+		if v, ok := optionMap["season"]; ok {
+			season = v.StringValue()
+			period = PeriodSeason
+		}
+
+		metric := MetricTime
+		if v, ok := optionMap["metric"]; ok {
+			metric = v.StringValue()
+		}
+
+		if metric != MetricTime && period != "" && period != PeriodAllTime {
+			cmdError(s, i, ErrMetricPeriod)
+			return
+		}
+		if friends && period != "" && period != PeriodAllTime {
+			cmdError(s, i, ErrFriendsPeriod)
+			return
+		}
+
+		key, err := periodKey(period, time.Now())
+		if season != "" {
+			key, err = seasonPeriodKey(season), nil
+			if _, ok := getSeason(season); !ok {
+				err = ErrUnknownSeason
+			}
+		}
+		if err != nil {
+			cmdError(s, i, err)
+			return
+		}
 
-		embed, err := generateLeaderboardEmbed(targetGuild, guildName, optionMap["difficulty"].StringValue())
+		embed, components, err := generateLeaderboardEmbed(locale, guildName, leaderboardQuery{
+			GuildID:    targetGuild,
+			Difficulty: optionMap["difficulty"].StringValue(),
+			Metric:     metric,
+			Period:     key,
+		})
 		if err != nil {
 			cmdError(s, i, err)
 			return
 		}
 
 		if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Embeds: &[]*discordgo.MessageEmbed{&embed},
+			Embeds:     &[]*discordgo.MessageEmbed{&embed},
+			Components: &components,
 		}); err != nil {
 			cmdError(s, i, err)
 		}
@@ -228,6 +450,7 @@ var CommandHandlers = map[string]func(s *discordgo.Session, i *discordgo.Interac
 		// Convert options to map.
 		optionMap := mapOptions(i.ApplicationCommandData().Options)
 		userID, _ := getUserID(i)
+		locale := interactionLocale(i)
 
 		var targetID string
 		target, ok := optionMap["target"]
@@ -263,48 +486,85 @@ var CommandHandlers = map[string]func(s *discordgo.Session, i *discordgo.Interac
 
 		var fields []*discordgo.MessageEmbedField
 
+		neverPlayed := tr(locale, "profile.neverPlayed")
 		difficulties := map[string]*DifficultyLevel{
-			"easy":   {PB: "Never played", PW: "Never played"},
-			"medium": {PB: "Never played", PW: "Never played"},
-			"hard":   {PB: "Never played", PW: "Never played"},
+			"easy":   {PB: neverPlayed, PW: neverPlayed},
+			"medium": {PB: neverPlayed, PW: neverPlayed},
+			"hard":   {PB: neverPlayed, PW: neverPlayed},
+		}
+
+		unranked := tr(locale, "profile.unranked")
+		ranks := map[string]string{
+			"easy":   unranked,
+			"medium": unranked,
+			"hard":   unranked,
+		}
+		for _, difficulty := range difficultyOrder {
+			rank, total, ok := getLeaderboardRank("global", difficultyFromString(difficulty), "", PeriodAllTime, targetID)
+			if ok {
+				ranks[difficulty] = tr(locale, "profile.rank", formatNumber(rank), formatNumber(total))
+			}
 		}
 
 		for level, data := range userData.Difficulties {
 			pbd, err := time.ParseDuration(fmt.Sprintf("%fs", data.PB))
 			if err == nil && pbd.Seconds() != 0 {
-				difficulties[level].PB = humanizetime.HumanizeDuration(pbd, 3)
+				difficulties[level].PB = humanizeDuration(locale, pbd, 3)
 			}
 
 			pwd, err := time.ParseDuration(fmt.Sprintf("%fs", data.PW))
 			if err == nil && pwd.Seconds() != 0 {
-				difficulties[level].PW = humanizetime.HumanizeDuration(pwd, 3)
+				difficulties[level].PW = humanizeDuration(locale, pwd, 3)
 			}
 		}
 		var title string
 		var desc string
 		if !viewAchievements {
-			title = fmt.Sprintf("%s's Stats", userString)
+			title = tr(locale, "profile.statsTitle", userString)
 			for _, difficulty := range difficultyOrder {
 				difficultyData := difficulties[difficulty]
-				fieldValue := fmt.Sprintf(UserStatsFormatString,
+				// The best streak is only stored once a streak ends or beats it.
+				bestStreak := userData.Difficulties[difficulty].BestStreak
+				if streak := userData.Difficulties[difficulty].WinStreak; streak > bestStreak {
+					bestStreak = streak
+				}
+				fieldValue := tr(locale, "profile.stats",
 					userData.Difficulties[difficulty].Wins,
 					userData.Difficulties[difficulty].Losses,
 					userData.Difficulties[difficulty].WinStreak,
+					bestStreak,
 					difficultyData.PB,
-					difficultyData.PW)
+					difficultyData.PW,
+					ranks[difficulty],
+					formatRating(locale, userData.Difficulties[difficulty]))
 
 				fields = append(fields, &discordgo.MessageEmbedField{
-					Name:   fmt.Sprintf("Stats for **%s** mode", strings.ToUpper(difficulty)),
+					Name:   tr(locale, "profile.statsField", strings.ToUpper(difficulty)),
 					Value:  fieldValue,
 					Inline: true,
 				})
 			}
+			fields = append(fields, &discordgo.MessageEmbedField{
+				Name:  tr(locale, "profile.badges"),
+				Value: badgeSummary(locale, userData),
+			})
+			fields = append(fields, &discordgo.MessageEmbedField{
+				Name:  tr(locale, "profile.coins"),
+				Value: formatNumber(userData.Balance),
+			})
+			fields = append(fields, &discordgo.MessageEmbedField{
+				Name:  tr(locale, "profile.streakShields"),
+				Value: tr(locale, "profile.streakShieldCount", userData.StreakShields, maxStreakShields),
+			})
 		}
 		var components []discordgo.MessageComponent
 		if viewAchievements {
-			title = fmt.Sprintf("%s's Achievements", userString)
-			fields, components = getFieldsAndComponents(userData, 0)
-			desc = "Page #1"
+			title = tr(locale, "profile.achievementsTitle", userString)
+			fields, components = getFieldsAndComponents(locale, userData, 0)
+			desc = tr(locale, "profile.page", 1)
+		}
+		if item, ok := equippedItem(userData, userData.Settings.Badge, ItemBadge); ok {
+			title = item.Value + " " + title
 		}
 
 		embed := discordgo.MessageEmbed{
@@ -330,7 +590,87 @@ var CommandHandlers = map[string]func(s *discordgo.Session, i *discordgo.Interac
 			fmt.Println(err)
 		}
 	},
-	"admin": AdminCommand,
+	"matchmake": func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		defer func() {
+			if err := recover(); err != nil {
+				handlePanic(err)
+			}
+		}()
+		optionMap := mapOptions(i.ApplicationCommandData().Options)
+		userID, isGuild := getUserID(i)
+		locale := interactionLocale(i)
+
+		replyContent := ""
+		if !isGuild {
+			replyContent = tr(locale, "matchmake.guildOnly")
+		}
+		if _, ok := getGame(userID); ok {
+			replyContent = tr(locale, "game.alreadyOpenShort")
+		}
+		if replyContent != "" {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Flags:   1 << 6,
+					Content: replyContent,
+				},
+			})
+			return
+		}
+		if !gameChannelAllowed(s, i) {
+			return
+		}
+
+		// Respond with a deferred message update initially, the game board replaces it once matched.
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		})
+
+		difficulty := optionMap["difficulty"].StringValue()
+		rating := ratingOf(getUserData(userID).Difficulties[difficulty])
+
+		content := tr(locale, "matchmake.searching", strings.ToUpper(difficulty), rating)
+		components := []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						CustomID: "matchmakeleave",
+						Label:    tr(locale, "matchmake.leave"),
+						Style:    discordgo.DangerButton,
+					},
+				},
+			},
+		}
+		if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content:    &content,
+			Components: &components,
+		}); err != nil {
+			cmdError(s, i, err)
+			return
+		}
+
+		if !joinMatchmaking(i.GuildID, difficulty, &queuedPlayer{
+			UserID:      userID,
+			Rating:      rating,
+			JoinedAt:    time.Now(),
+			Interaction: i,
+		}) {
+			content := tr(locale, "matchmake.alreadyQueued")
+			components := []discordgo.MessageComponent{}
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content:    &content,
+				Components: &components,
+			})
+		}
+	},
+	"admin":     AdminCommand,
+	"stats":     StatsCommand,
+	"settings":  SettingsCommand,
+	"config":    ConfigCommand,
+	"shop":      ShopCommand,
+	"subscribe": SubscribeCommand,
+	"friends":   FriendsCommand,
+	"spectate":  SpectateCommand,
 }
 
 // Map unique IDs of components to their respected handler.
@@ -345,10 +685,10 @@ var ComponentHandlers = map[string]func(s *discordgo.Session, i *discordgo.Inter
 		userID, _ := getUserID(i)
 
 		// Check if the user has a game open.
-		game, ok := Games[userID]
+		game, ok := getGame(userID)
 		if !ok {
 			// User does not have a game open, send an error message.
-			replyContent := "You don't have a game open!"
+			replyContent := tr(interactionLocale(i), "game.noGame")
 			go s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
@@ -362,7 +702,7 @@ var ComponentHandlers = map[string]func(s *discordgo.Session, i *discordgo.Inter
 		// Check if the flag button is associated with the user's game.
 		if game.FlagID != i.Message.ID {
 			// Flag button does not belong to the user's game, send an error message.
-			replyContent := "This is not your game."
+			replyContent := tr(interactionLocale(i), "game.notYours")
 			go s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
@@ -379,36 +719,15 @@ var ComponentHandlers = map[string]func(s *discordgo.Session, i *discordgo.Inter
 		})
 
 		// Toggle the flag status.
+		game.mutex.Lock()
 		game.Flags ^= FlagEnabled
-
-		// Create the new flag button.
-		flagRow := discordgo.ActionsRow{}
-		flagButton := &discordgo.Button{
-			CustomID: "minesweeperflagbutton",
-			Style:    discordgo.DangerButton,
-			Label:    "OFF",
-			Emoji: discordgo.ComponentEmoji{
-				Name: "🚩",
-			},
-		}
-		endGameButton := &discordgo.Button{
-			CustomID: "endgamebutton",
-			Style:    discordgo.DangerButton,
-			Label:    "End game",
-		}
-
-		// Update the label and style of the flag button based on the flag status.
-		if game.Flags&FlagEnabled != 0 {
-			flagButton.Label = "ON"
-			flagButton.Style = discordgo.SuccessButton
-		}
-		flagRow.Components = append(flagRow.Components, flagButton, endGameButton)
+		game.mutex.Unlock()
 
 		// Edit the old flag message with the new button.
 		if _, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
 			Channel:    game.ChannelID,
 			ID:         game.FlagID,
-			Components: []discordgo.MessageComponent{flagRow},
+			Components: []discordgo.MessageComponent{generateFlagRow(game)},
 		}); err != nil {
 			cmdError(s, i, err)
 			return
@@ -429,10 +748,17 @@ var ComponentHandlers = map[string]func(s *discordgo.Session, i *discordgo.Inter
 		userID, _ := getUserID(i)
 
 		// Check if the user has a game open.
-		game, ok := Games[userID]
+		game, ok := getGame(userID)
+
+		// Game moderators can end the game the button belongs to.
+		moderated := false
+		if other, found := gameByFlagID(i.Message.ID); found && other.UserID != userID && canModerateGames(i) {
+			game, ok, moderated = other, true, true
+		}
+
 		if !ok {
 			// User does not have a game open, send an error message.
-			replyContent := "You don't have a game open!"
+			replyContent := tr(interactionLocale(i), "game.noGame")
 			go s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
@@ -444,8 +770,46 @@ var ComponentHandlers = map[string]func(s *discordgo.Session, i *discordgo.Inter
 		}
 
 		// Handle the end of the game.
+		if !moderated {
+			game.Interaction = i.Interaction
+		}
 		HandleGameEnd(s, game, minesweeper.ManualEnd, false)
 	},
+	"matchmakeleave": func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		defer func() {
+			if err := recover(); err != nil {
+				handlePanic(err)
+			}
+		}()
+		userID, _ := getUserID(i)
+
+		// Only the player who queued may leave the queue.
+		if i.Message.Interaction == nil || i.Message.Interaction.User.ID != userID {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Flags:   1 << 6,
+					Content: tr(interactionLocale(i), "matchmake.notYourQueue"),
+				},
+			})
+			return
+		}
+
+		content := tr(interactionLocale(i), "matchmake.left")
+		if _, ok := leaveMatchmaking(userID); !ok {
+			content = tr(interactionLocale(i), "matchmake.notQueued")
+		}
+
+		if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    content,
+				Components: []discordgo.MessageComponent{},
+			},
+		}); err != nil {
+			cmdError(s, i, err)
+		}
+	},
 	"profileleft": func(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		defer func() {
 			if err := recover(); err != nil {
@@ -476,7 +840,7 @@ var ComponentHandlers = map[string]func(s *discordgo.Session, i *discordgo.Inter
 			userImage = user.AvatarURL("")
 		}
 
-		page, err := strconv.Atoi(embed.Description[6:])
+		page, err := strconv.Atoi(embed.Description[strings.LastIndex(embed.Description, "#")+1:])
 		if err != nil {
 			cmdError(s, i, err)
 			return
@@ -490,9 +854,10 @@ var ComponentHandlers = map[string]func(s *discordgo.Session, i *discordgo.Inter
 
 		userData := getUserData(target)
 
-		title := fmt.Sprintf("%s's Achievements", userString)
-		fields, components := getFieldsAndComponents(userData, page)
-		desc := fmt.Sprintf("Page #%d", page+1)
+		locale := interactionLocale(i)
+		title := tr(locale, "profile.achievementsTitle", userString)
+		fields, components := getFieldsAndComponents(locale, userData, page)
+		desc := tr(locale, "profile.page", page+1)
 
 		newEmbed := discordgo.MessageEmbed{
 			Type:        discordgo.EmbedTypeRich,
@@ -547,7 +912,7 @@ var ComponentHandlers = map[string]func(s *discordgo.Session, i *discordgo.Inter
 			userImage = user.AvatarURL("")
 		}
 
-		page, err := strconv.Atoi(embed.Description[6:])
+		page, err := strconv.Atoi(embed.Description[strings.LastIndex(embed.Description, "#")+1:])
 		if err != nil {
 			cmdError(s, i, err)
 			return
@@ -559,9 +924,10 @@ var ComponentHandlers = map[string]func(s *discordgo.Session, i *discordgo.Inter
 
 		userData := getUserData(target)
 
-		title := fmt.Sprintf("%s's Achievements", userString)
-		fields, components := getFieldsAndComponents(userData, page)
-		desc := fmt.Sprintf("Page #%d", page+1)
+		locale := interactionLocale(i)
+		title := tr(locale, "profile.achievementsTitle", userString)
+		fields, components := getFieldsAndComponents(locale, userData, page)
+		desc := tr(locale, "profile.page", page+1)
 
 		newEmbed := discordgo.MessageEmbed{
 			Type:        discordgo.EmbedTypeRich,
@@ -586,6 +952,66 @@ var ComponentHandlers = map[string]func(s *discordgo.Session, i *discordgo.Inter
 			fmt.Println(err)
 		}
 	},
+	"settingsdifficulty":   settingsSelectHandler("defaultDifficulty"),
+	"settingstheme":        settingsSelectHandler("theme"),
+	"settingsflagmode":     settingsToggleHandler("settingsflagmode"),
+	"settingsping":         settingsToggleHandler("settingsping"),
+	"settingsephemeral":    settingsToggleHandler("settingsephemeral"),
+	"settingshideglobal":   settingsToggleHandler("settingshideglobal"),
+	"settingsstreakshield": settingsToggleHandler("settingsstreakshield"),
+	"settingsbeatendm":     settingsToggleHandler("settingsbeatendm"),
+	"settingsspectators":   settingsToggleHandler("settingsspectators"),
+	"spectatebutton":       SpectateButton,
+}
+
+// startCustomGame starts a custom game, ranked on the leaderboard of its preset if the configuration has one.
+func startCustomGame(s *discordgo.Session, i *discordgo.InteractionCreate, bombs int64, allowSurroundingBombs, noStartSpot bool, userID string) {
+	Game := minesweeper.NewGame(minesweeper.Custom, int(bombs), allowSurroundingBombs, noStartSpot)
+	StartGame(s, i, Game, "custom", userID)
+
+	game, ok := getGame(userID)
+	if !ok {
+		return
+	}
+
+	if preset, ok := getPresetByKey(presetKey(bombs, allowSurroundingBombs, noStartSpot)); ok {
+		game.Preset = preset.Key
+	}
+}
+
+// Handle the page buttons of a leaderboard.
+func HandleLeaderboardPage(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+	query, err := parseLeaderboardQuery(i.MessageComponentData().CustomID)
+	if err != nil {
+		cmdError(s, i, err)
+		return
+	}
+
+	if query.Page < 0 {
+		query.Page = 0
+	}
+
+	locale := interactionLocale(i)
+	embed, components, err := generateLeaderboardEmbed(locale, leaderboardGuildName(locale, query.GuildID), query)
+	if err != nil {
+		cmdError(s, i, err)
+		return
+	}
+
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{&embed},
+			Components: components,
+		},
+	}); err != nil {
+		cmdError(s, i, err)
+	}
 }
 
 // Handle user interactions to the minesweeper board.
@@ -597,9 +1023,9 @@ func HandleBoard(s *discordgo.Session, i *discordgo.InteractionCreate, positionx
 	}()
 	// Retrieve the user ID and check if a game exists for the user
 	userID, _ := getUserID(i)
-	game, ok := Games[userID]
+	game, ok := getGame(userID)
 	if !ok {
-		replyContent := "You don't have a game open!"
+		replyContent := tr(interactionLocale(i), "game.noGame")
 		go s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -612,7 +1038,7 @@ func HandleBoard(s *discordgo.Session, i *discordgo.InteractionCreate, positionx
 
 	// Check if the game's board ID matches the ID of the triggering message
 	if game.BoardID != i.Message.ID {
-		replyContent := "This is not your game."
+		replyContent := tr(interactionLocale(i), "game.notYours")
 		go s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -623,6 +1049,22 @@ func HandleBoard(s *discordgo.Session, i *discordgo.InteractionCreate, positionx
 		return
 	}
 
+	game.mutex.Lock()
+	defer game.mutex.Unlock()
+
+	// A click that waited for another one to end the game has nothing left to do.
+	select {
+	case <-game.Removed:
+		go s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
+		})
+		return
+	default:
+	}
+
+	// Keep the interaction to send ephemeral results with.
+	game.Interaction = i.Interaction
+
 	// Set the start time if it hasn't been set yet
 	if game.StartTime.IsZero() {
 		game.StartTime = time.Now()
@@ -630,10 +1072,9 @@ func HandleBoard(s *discordgo.Session, i *discordgo.InteractionCreate, positionx
 
 	// Find the spot on the game board based on the provided coordinates
 	spot := game.Game.FindSpot(positionx, positiony)
+	game.Clicks++
 
-	for id, achievement := range AwardAchievements(game, minesweeper.Nothing, spot, false, false, true) {
-		game.Achievements[id] = achievement
-	}
+	game.queueAchievements(AchievementClick, minesweeper.Nothing, spot, false, false)
 
 	// Perform the appropriate action based on the FlagEnabled flag
 	switch game.Flags & FlagEnabled {
@@ -645,18 +1086,17 @@ func HandleBoard(s *discordgo.Session, i *discordgo.InteractionCreate, positionx
 		)
 		if spot.DisplayedType == minesweeper.Normal {
 			game.Flags |= HasChorded
+			game.Chords++
 			event = game.Game.ChordSpot(spot)
 			chord = true
+			game.queueAchievements(AchievementReveal, event, spot, chord, false)
 		}
 		if event != minesweeper.Nothing {
-			for id, achievement := range AwardAchievements(game, event, spot, chord, false, false) {
-				game.Achievements[id] = achievement
-			}
-			// Handle the game end and respond with a deferred message update
-			HandleGameEnd(s, game, event, true)
-			go s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			// Acknowledge the click first, ending the game can take longer than Discord waits for it.
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseDeferredMessageUpdate,
 			})
+			endGame(s, game, event, true)
 			return
 		}
 		// If chorded the spot, do nothing else.
@@ -668,15 +1108,13 @@ func HandleBoard(s *discordgo.Session, i *discordgo.InteractionCreate, positionx
 		}
 		// Visit the spot and check if the game ends
 		gameEnd, event = game.Game.VisitSpot(spot)
-		for id, achievement := range AwardAchievements(game, event, spot, chord, false, false) {
-			game.Achievements[id] = achievement
-		}
+		game.queueAchievements(AchievementReveal, event, spot, chord, false)
 		if gameEnd {
-			// Handle the game end and respond with a deferred message update
-			HandleGameEnd(s, game, event, true)
-			go s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			// Acknowledge the click first, ending the game can take longer than Discord waits for it.
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseDeferredMessageUpdate,
 			})
+			endGame(s, game, event, true)
 			return
 		}
 	default:
@@ -686,18 +1124,17 @@ func HandleBoard(s *discordgo.Session, i *discordgo.InteractionCreate, positionx
 		)
 		if spot.DisplayedType == minesweeper.Normal {
 			game.Flags |= HasChorded
+			game.Chords++
 			event = game.Game.ChordSpot(spot)
 			chord = true
+			game.queueAchievements(AchievementReveal, event, spot, chord, false)
 		}
 		if event != minesweeper.Nothing {
-			// Handle the game end and respond with a deferred message update
-			HandleGameEnd(s, game, event, true)
-			go s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			// Acknowledge the click first, ending the game can take longer than Discord waits for it.
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseDeferredMessageUpdate,
 			})
-			for id, achievement := range AwardAchievements(game, event, spot, chord, false, false) {
-				game.Achievements[id] = achievement
-			}
+			endGame(s, game, event, true)
 			return
 		}
 		// If chorded the spot, do nothing else.
@@ -709,14 +1146,13 @@ func HandleBoard(s *discordgo.Session, i *discordgo.InteractionCreate, positionx
 			break
 		}
 		game.Flags |= HasUsedFlag
+		game.FlagClicks++
 		game.Game.FlagSpot(spot)
-		for id, achievement := range AwardAchievements(game, event, spot, chord, true, false) {
-			game.Achievements[id] = achievement
-		}
+		game.queueAchievements(AchievementFlag, event, spot, chord, true)
 	}
 
 	// Update the game board message with the new content and components
-	content := fmt.Sprintf("Here you go >~<\nTotal bombs: **%d**", game.Game.TotalBombs)
+	content := tr(game.Locale, "game.board", game.Game.TotalBombs)
 	board := GenerateBoard(game, false, false)
 	editMessage := &discordgo.MessageEdit{
 		Channel:    game.ChannelID,
@@ -786,7 +1222,7 @@ func cmdError(s *discordgo.Session, i *discordgo.InteractionCreate, err error) {
 	go s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("An error occurred!\n```%s```", err.Error()),
+			Content: tr(interactionLocale(i), "error.generic", localizeError(interactionLocale(i), err).Error()),
 		},
 	})
 }
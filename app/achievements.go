@@ -1,337 +1,807 @@
 package main
 
 import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
 	"main/minesweeper"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
-type CheckData struct {
-	Event       int
-	Game        *MinesweeperGame
-	ClickedCell *minesweeper.Spot
-	Flagged     bool
-	Chorded     bool
-	PreVisit    bool
+// Achievements shipped with the bot, see achievementCriterionTypes for what they can check.
+//
+//go:embed achievements.json
+var achievementsFile []byte
+
+// Moments of a game achievements are checked at.
+const (
+	// A cell was clicked, before it is revealed.
+	AchievementClick = iota
+	// A click revealed or chorded cells, or ended the game.
+	AchievementReveal
+	// A cell was flagged.
+	AchievementFlag
+	// The game has ended and the stats of the player are updated.
+	AchievementEnd
+)
+
+var allAchievementKinds = []int{AchievementClick, AchievementReveal, AchievementFlag, AchievementEnd}
+
+// State of a game achievements are checked against. It is copied out of the game when it is
+// queued, as the game keeps changing while the achievements are checked in the background.
+type AchievementSnapshot struct {
+	Kind       int
+	Event      int
+	Difficulty string
+	Flags      int64
+	Started    bool
+	Duration   time.Duration
+	Flagged    bool
+	Chorded    bool
+	// Cell that was clicked, nil if the snapshot is not about a click.
+	Cell *CellSnapshot
+	// Stats of the player by difficulty, only known once the game has ended.
+	Stats map[string]DifficultyData
+}
+
+type CellSnapshot struct {
+	Type          int
+	DisplayedType int
+	// No cell around it had been revealed.
+	Blind bool
+	// A diagonal neighbor had all its safe cells revealed, so the cell was obviously a bomb.
+	Obvious bool
+}
+
+// A condition of an achievement, which fields are used depends on the type.
+type AchievementCriterion struct {
+	Type string `json:"type"`
+	// Event the check was caused by, for event criteria.
+	Event string `json:"event,omitempty"`
+	// Something the player did, for action criteria.
+	Action string `json:"action,omitempty"`
+	// Stat of the player that has to reach the target on any difficulty, for count criteria.
+	Stat   string `json:"stat,omitempty"`
+	Target int64  `json:"target,omitempty"`
+	// Bounds of the game duration in seconds, for time criteria. Zero leaves a bound open.
+	MinSeconds float64 `json:"minSeconds,omitempty"`
+	MaxSeconds float64 `json:"maxSeconds,omitempty"`
+	// Inverts the criterion.
+	Not bool `json:"not,omitempty"`
 }
 
 type Achievement struct {
-	Name        string
-	Description string
-	CheckFunc   func(data CheckData) bool
-}
-
-var Achievements = map[int]Achievement{
-	// Win a game.
-	0: {
-		Name:        "Basic reasoning",
-		Description: "Win your first game of minesweeper",
-		CheckFunc: func(data CheckData) bool {
-			return data.Event == minesweeper.Won
-		},
-	},
-	// Lose a game.
-	1: {
-		Name:        "U MAD?",
-		Description: "Lose your first game of minesweeper",
-		CheckFunc: func(data CheckData) bool {
-			return data.Event == minesweeper.Lost
-		},
-	},
-	// Failed to chord.
-	2: {
-		Name:        "Out Of Tune",
-		Description: "Tried to chord, but you were out of tune.",
-		CheckFunc: func(data CheckData) bool {
-			if !data.Chorded {
-				return false
-			}
-			return data.Event == minesweeper.Lost
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Tiered achievements are unlocked in the tier of the difficulty they were earned on, and
+	// count the stats of that difficulty only.
+	Tiered bool `json:"tiered,omitempty"`
+	// Secret achievements only show a hint, if any, until they are unlocked.
+	Secret   bool                   `json:"secret,omitempty"`
+	Hint     string                 `json:"hint,omitempty"`
+	Criteria []AchievementCriterion `json:"criteria"`
+}
+
+type AchievementTier struct {
+	Name       string
+	Difficulty string
+	Badge      string
+}
+
+// Tiers of tiered achievements, from the lowest to the highest.
+var AchievementTiers = []AchievementTier{
+	{Name: "bronze", Difficulty: "easy", Badge: "🥉"},
+	{Name: "silver", Difficulty: "medium", Badge: "🥈"},
+	{Name: "gold", Difficulty: "hard", Badge: "🥇"},
+}
+
+var Achievements = make(map[int]Achievement)
+
+// IDs of the achievements in the order they are listed in.
+var AchievementOrder []int
+
+// Achievements by the moments of a game they can be unlocked at.
+var achievementsByKind = make(map[int][]Achievement)
+
+// Checks a criterion, along with the moments of a game it can be true at.
+type achievementEvaluator struct {
+	Kinds []int
+	Check func(c AchievementCriterion, data AchievementSnapshot) bool
+}
+
+var achievementCriterionTypes = map[string]achievementEvaluator{
+	"event": {
+		Kinds: []int{AchievementReveal, AchievementEnd},
+		Check: func(c AchievementCriterion, data AchievementSnapshot) bool {
+			return data.Event == achievementEvents[c.Event]
 		},
 	},
-	// Successfully chord.
-	3: {
-		Name:        "Wonderful music!",
-		Description: "Successfully chord",
-		CheckFunc: func(data CheckData) bool {
-			if !data.Chorded {
-				return false
-			}
-			return data.Event != minesweeper.Lost
+	// Actions declare their own moments, see achievementActions.
+	"action": {
+		Check: func(c AchievementCriterion, data AchievementSnapshot) bool {
+			return achievementActions[c.Action].Check(c, data)
 		},
 	},
-	// Randomly click the board without any neighboring cells.
-	4: {
-		Name:        "YOLO",
-		Description: "Clicked a cell without any surrounding visited cells",
-		CheckFunc: func(data CheckData) bool {
-			if data.ClickedCell == nil {
-				return false
-			}
-			if data.ClickedCell.DisplayedType == minesweeper.StartHere {
-				return false
-			}
-			for _, spot := range data.ClickedCell.SurroundingSpots {
-				if spot.DisplayedType != minesweeper.Hidden {
-					return false
-				}
-			}
-			return true
+	"count": {
+		Kinds: []int{AchievementEnd},
+		Check: func(c AchievementCriterion, data AchievementSnapshot) bool {
+			return data.Stats != nil && c.progress(data.Stats) >= c.Target
 		},
 	},
-	// The hardest achievement in the game, for now.
-	5: {
-		Name:        "⬧︎♓︎●︎●︎⍓︎ ♍︎♋︎⧫︎ ⬧︎♋︎⍓︎⬧︎ ♒︎♓︎✏︎",
-		Description: "🖳︎🗏︎",
-		CheckFunc: func(data CheckData) bool {
-			userData := getUserData(data.Game.UserID)
-
-			if userData.Difficulties[data.Game.Difficulty].WinStreak < 69 {
-				return false
-			}
-
-			if data.Game.Flags&HasNormalClicked != 0 {
-				return false
-			}
-
-			if data.Game.Flags&HasUsedFlag == 0 {
+	"time": {
+		Kinds: []int{AchievementReveal, AchievementEnd},
+		Check: func(c AchievementCriterion, data AchievementSnapshot) bool {
+			if !data.Started {
 				return false
 			}
-
-			if data.Game.Flags&HasChorded == 0 {
-				return false
-			}
-
-			if time.Since(data.Game.StartTime).Seconds() > 20 {
+			seconds := data.Duration.Seconds()
+			if c.MinSeconds != 0 && seconds < c.MinSeconds {
 				return false
 			}
-
-			return true
+			return c.MaxSeconds == 0 || seconds <= c.MaxSeconds
 		},
 	},
-	// Clicked on a bomb that was obviously a bomb. i.e. 01x 011 000.
-	6: {
-		Name:        "Can't Count",
-		Description: "How did you manage this?",
-		CheckFunc: func(data CheckData) bool {
-			if data.ClickedCell == nil {
-				return false
-			}
-			if data.Event != minesweeper.Lost {
-				return false
-			}
-			if data.ClickedCell.Type != minesweeper.Bomb {
-				return false
-			}
+}
 
-			offsets := []struct {
-				x int
-				y int
-			}{
-				{-1, 1},
-				{1, 1},
-				{-1, -1},
-				{1, -1},
-			}
+var achievementEvents = map[string]int{
+	"nothing":   minesweeper.Nothing,
+	"manualEnd": minesweeper.ManualEnd,
+	"timedEnd":  minesweeper.TimedEnd,
+	"lost":      minesweeper.Lost,
+	"won":       minesweeper.Won,
+}
 
-			for _, offset := range offsets {
-				x, y := data.ClickedCell.X+offset.x, data.ClickedCell.Y+offset.y
-				surroundingSpot := data.Game.Game.FindSpot(x, y)
-				if surroundingSpot == nil {
-					continue
-				}
-				count := 0
-				for _, cell := range surroundingSpot.SurroundingSpots {
-					if cell.DisplayedType == minesweeper.Normal {
-						count++
-					}
-				}
-				if count == (len(surroundingSpot.SurroundingSpots) - surroundingSpot.NearbyBombs) {
-					return true
-				}
-			}
+var achievementStats = map[string]func(dd DifficultyData) int64{
+	"wins":   func(dd DifficultyData) int64 { return dd.Wins },
+	"losses": func(dd DifficultyData) int64 { return dd.Losses },
+	"streak": func(dd DifficultyData) int64 { return dd.WinStreak },
+}
 
-			return false
+var achievementActions = map[string]achievementEvaluator{
+	"chorded": {
+		Kinds: []int{AchievementReveal},
+		Check: func(c AchievementCriterion, data AchievementSnapshot) bool {
+			return data.Chorded
 		},
 	},
-	7: {
-		Name:        "Not so nice.",
-		Description: "Lose 69 times on any difficulty",
-		CheckFunc: func(data CheckData) bool {
-			userData := getUserData(data.Game.UserID)
-			return userData.Difficulties[data.Game.Difficulty].Losses >= 69
+	"usedFlag": {
+		Kinds: []int{AchievementReveal, AchievementEnd},
+		Check: func(c AchievementCriterion, data AchievementSnapshot) bool {
+			return data.Flags&HasUsedFlag != 0
 		},
 	},
-	8: {
-		Name:        "Nice.",
-		Description: "Win 69 times on any difficulty",
-		CheckFunc: func(data CheckData) bool {
-			userData := getUserData(data.Game.UserID)
-			return userData.Difficulties[data.Game.Difficulty].Wins >= 69
+	// Cleared cells by chording only, apart from the start cell.
+	"onlyChorded": {
+		Kinds: []int{AchievementReveal, AchievementEnd},
+		Check: func(c AchievementCriterion, data AchievementSnapshot) bool {
+			return data.Flags&HasNormalClicked == 0 && data.Flags&HasChorded != 0
 		},
 	},
-	9: {
-		Name:        "That's real nice!",
-		Description: "Get a 69 win streak on any difficulty",
-		CheckFunc: func(data CheckData) bool {
-			userData := getUserData(data.Game.UserID)
-			return userData.Difficulties[data.Game.Difficulty].WinStreak >= 69
+	"flaggedBomb": {
+		Kinds: []int{AchievementFlag},
+		Check: func(c AchievementCriterion, data AchievementSnapshot) bool {
+			return data.Flagged && data.Cell != nil && data.Cell.Type == minesweeper.Bomb
 		},
 	},
-	10: {
-		Name:        "Flagged by the CIA",
-		Description: "Flag a cell as a bomb correctly",
-		CheckFunc: func(data CheckData) bool {
-			if data.ClickedCell == nil {
-				return false
-			}
-			if !data.Flagged {
-				return false
-			}
-			if data.ClickedCell.Type != minesweeper.Bomb {
-				return false
-			}
-			return true
-		},
-	},
-	11: {
-		Name:        "Nuh uh!",
-		Description: "Flag a cell as a bomb incorrectly",
-		CheckFunc: func(data CheckData) bool {
-			if data.ClickedCell == nil {
-				return false
-			}
-			if !data.Flagged {
-				return false
-			}
-			if data.ClickedCell.Type == minesweeper.Bomb {
-				return false
-			}
-			return true
+	"flaggedSafe": {
+		Kinds: []int{AchievementFlag},
+		Check: func(c AchievementCriterion, data AchievementSnapshot) bool {
+			return data.Flagged && data.Cell != nil && data.Cell.Type != minesweeper.Bomb
 		},
 	},
-	12: {
-		Name:        "Stale bread",
-		Description: "Let the game time out",
-		CheckFunc: func(data CheckData) bool {
-			return data.Event == minesweeper.TimedEnd
+	// Randomly clicked the board without any neighboring cells.
+	"blindClick": {
+		Kinds: []int{AchievementClick},
+		Check: func(c AchievementCriterion, data AchievementSnapshot) bool {
+			return data.Cell != nil && data.Cell.DisplayedType != minesweeper.StartHere && data.Cell.Blind
 		},
 	},
-	13: {
-		Name:        "How lucky!",
-		Description: "Beat the game in a single click",
-		CheckFunc: func(data CheckData) bool {
-			if data.Event != minesweeper.Won {
-				return false
-			}
-			gameDuration := time.Since(data.Game.StartTime)
-			return gameDuration.Seconds() <= 0.2
+	// Clicked on a bomb that was obviously a bomb. i.e. 01x 011 000.
+	"obviousBomb": {
+		Kinds: []int{AchievementReveal},
+		Check: func(c AchievementCriterion, data AchievementSnapshot) bool {
+			return data.Cell != nil && data.Cell.Type == minesweeper.Bomb && data.Cell.Obvious
 		},
 	},
-	14: {
-		Name:        "Purity",
-		Description: "Complete the game with nothing but chording",
-		CheckFunc: func(data CheckData) bool {
-			if data.Game.Flags&HasNormalClicked != 0 {
-				return false
-			}
-			if data.Game.Flags&HasChorded == 0 {
-				return false
+}
+
+// Gets whether none of the cells around the spot are revealed.
+func blindSpot(spot *minesweeper.Spot) bool {
+	for _, surrounding := range spot.SurroundingSpots {
+		if surrounding.DisplayedType != minesweeper.Hidden {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Gets whether a diagonal neighbor of the spot had all its safe cells revealed, leaving the
+// spot as the only place its bomb could be.
+func obviousBomb(game *minesweeper.Game, spot *minesweeper.Spot) bool {
+	offsets := []struct {
+		x int
+		y int
+	}{
+		{-1, 1},
+		{1, 1},
+		{-1, -1},
+		{1, -1},
+	}
+
+	for _, offset := range offsets {
+		x, y := spot.X+offset.x, spot.Y+offset.y
+		surroundingSpot := game.FindSpot(x, y)
+		if surroundingSpot == nil {
+			continue
+		}
+		count := 0
+		for _, cell := range surroundingSpot.SurroundingSpots {
+			if cell.DisplayedType == minesweeper.Normal {
+				count++
 			}
+		}
+		if count == (len(surroundingSpot.SurroundingSpots) - surroundingSpot.NearbyBombs) {
 			return true
-		},
-	},
+		}
+	}
 
-	// Speed based achievements.
-	15: {
-		Name:        "Average.",
-		Description: "Not too fast, not too slow, but good job, I guess.",
-		CheckFunc: func(data CheckData) bool {
-			if data.Event != minesweeper.Won {
-				return false
-			}
-			gameDuration := time.Since(data.Game.StartTime)
-			return gameDuration.Seconds() <= 60
-		},
-	},
-	16: {
-		Name:        "Faster",
-		Description: "You're gettin there!",
-		CheckFunc: func(data CheckData) bool {
-			if data.Event != minesweeper.Won {
-				return false
+	return false
+}
+
+func (c AchievementCriterion) validate() error {
+	if _, ok := achievementCriterionTypes[c.Type]; !ok {
+		return fmt.Errorf("unknown criterion type %q", c.Type)
+	}
+
+	switch c.Type {
+	case "event":
+		if _, ok := achievementEvents[c.Event]; !ok {
+			return fmt.Errorf("unknown event %q", c.Event)
+		}
+	case "action":
+		if _, ok := achievementActions[c.Action]; !ok {
+			return fmt.Errorf("unknown action %q", c.Action)
+		}
+	case "count":
+		if _, ok := achievementStats[c.Stat]; !ok {
+			return fmt.Errorf("unknown stat %q", c.Stat)
+		}
+		if c.Target <= 0 {
+			return fmt.Errorf("count of %s needs a positive target", c.Stat)
+		}
+	case "time":
+		if c.MinSeconds == 0 && c.MaxSeconds == 0 {
+			return fmt.Errorf("time criterion without bounds")
+		}
+	}
+
+	return nil
+}
+
+func (c AchievementCriterion) evaluator() achievementEvaluator {
+	if c.Type == "action" {
+		return achievementActions[c.Action]
+	}
+
+	return achievementCriterionTypes[c.Type]
+}
+
+func (c AchievementCriterion) check(data AchievementSnapshot) bool {
+	return c.evaluator().Check(c, data) != c.Not
+}
+
+// Gets the highest value of the stat of a count criterion across the difficulties.
+func (c AchievementCriterion) progress(stats map[string]DifficultyData) int64 {
+	var best int64
+	for _, dd := range stats {
+		if value := achievementStats[c.Stat](dd); value > best {
+			best = value
+		}
+	}
+
+	return best
+}
+
+// Gets the progress towards the count criterion of the achievement. Tiered achievements count
+// the stats of the difficulty of the lowest tier not reached yet, as each tier only counts its own.
+func (a Achievement) progress(counter AchievementCriterion, stats map[string]DifficultyData) int64 {
+	if !a.Tiered {
+		return counter.progress(stats)
+	}
+
+	var value int64
+	for _, tier := range AchievementTiers {
+		value = counter.progress(map[string]DifficultyData{tier.Difficulty: stats[tier.Difficulty]})
+		if value < counter.Target {
+			break
+		}
+	}
+
+	return value
+}
+
+func (a Achievement) check(data AchievementSnapshot) bool {
+	if a.Tiered && data.Stats != nil {
+		data.Stats = map[string]DifficultyData{data.Difficulty: data.Stats[data.Difficulty]}
+	}
+
+	for _, criterion := range a.Criteria {
+		if !criterion.check(data) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Gets the tier the achievement is unlocked in on a difficulty, empty if it isn't tiered.
+func (a Achievement) tier(difficulty string) string {
+	if !a.Tiered {
+		return ""
+	}
+	for _, tier := range AchievementTiers {
+		if tier.Difficulty == difficulty {
+			return tier.Name
+		}
+	}
+
+	return ""
+}
+
+// Gets the rank of a tier, higher tiers having higher ranks and no tier being -1.
+func tierRank(name string) int {
+	for index, tier := range AchievementTiers {
+		if tier.Name == name {
+			return index
+		}
+	}
+
+	return -1
+}
+
+func tierBadge(name string) string {
+	if rank := tierRank(name); rank >= 0 {
+		return AchievementTiers[rank].Badge
+	}
+
+	return ""
+}
+
+// Gets the moments of a game every criterion of the achievement can be true at.
+func (a Achievement) kinds() []int {
+	kinds := allAchievementKinds
+	for _, criterion := range a.Criteria {
+		evaluator := criterion.evaluator()
+		// An inverted criterion is true whenever the criterion itself can't be.
+		if criterion.Not {
+			continue
+		}
+
+		var shared []int
+		for _, kind := range kinds {
+			for _, other := range evaluator.Kinds {
+				if kind == other {
+					shared = append(shared, kind)
+				}
 			}
-			gameDuration := time.Since(data.Game.StartTime)
-			return gameDuration.Seconds() <= 40
-		},
-	},
-	17: {
-		Name:        "Pretty speedy!",
-		Description: "Wow, 20 seconds, great.",
-		CheckFunc: func(data CheckData) bool {
-			if data.Event != minesweeper.Won {
-				return false
+		}
+		kinds = shared
+	}
+
+	return kinds
+}
+
+// Gets the count criterion progress of the achievement is tracked with, if it has one.
+func (a Achievement) counter() (AchievementCriterion, bool) {
+	for _, criterion := range a.Criteria {
+		if criterion.Type == "count" && !criterion.Not {
+			return criterion, true
+		}
+	}
+
+	return AchievementCriterion{}, false
+}
+
+func parseAchievements(data []byte) ([]Achievement, error) {
+	var achievements []Achievement
+	if err := json.Unmarshal(data, &achievements); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool)
+	for _, achievement := range achievements {
+		if seen[achievement.ID] {
+			return nil, fmt.Errorf("duplicate achievement %d", achievement.ID)
+		}
+		seen[achievement.ID] = true
+
+		if achievement.Name == "" {
+			return nil, fmt.Errorf("achievement %d has no name", achievement.ID)
+		}
+		if len(achievement.Criteria) == 0 {
+			return nil, fmt.Errorf("achievement %d has no criteria", achievement.ID)
+		}
+		for _, criterion := range achievement.Criteria {
+			if err := criterion.validate(); err != nil {
+				return nil, fmt.Errorf("achievement %d: %v", achievement.ID, err)
 			}
-			gameDuration := time.Since(data.Game.StartTime)
-			return gameDuration.Seconds() <= 20
-		},
-	},
-	18: {
-		Name:        "Speed demon!",
-		Description: "That's not bad! 10 seconds!",
-		CheckFunc: func(data CheckData) bool {
-			if data.Event != minesweeper.Won {
-				return false
+		}
+		if len(achievement.kinds()) == 0 {
+			return nil, fmt.Errorf("achievement %d can't be unlocked, its criteria are never checked at the same moment", achievement.ID)
+		}
+	}
+
+	return achievements, nil
+}
+
+// Loads the achievements shipped with the bot.
+func loadAchievements() {
+	achievements, err := parseAchievements(achievementsFile)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, achievement := range achievements {
+		Achievements[achievement.ID] = achievement
+		AchievementOrder = append(AchievementOrder, achievement.ID)
+		for _, kind := range achievement.kinds() {
+			achievementsByKind[kind] = append(achievementsByKind[kind], achievement)
+		}
+	}
+	sort.Ints(AchievementOrder)
+
+	fmt.Printf("Loaded %d achievements\n", len(Achievements))
+}
+
+// Gets the name and description of the achievement in the language of the locale, the ones
+// in Achievements being the English ones.
+func (a Achievement) localized(locale string, id int) (string, string) {
+	name, description := a.Name, a.Description
+	if message, ok := Messages.Lookup(locale, fmt.Sprintf("achievement.%d.name", id)); ok {
+		name = message.Text
+	}
+	if message, ok := Messages.Lookup(locale, fmt.Sprintf("achievement.%d.description", id)); ok {
+		description = message.Text
+	}
+
+	return name, description
+}
+
+// Gets what is shown of the achievement while it is locked, which for secret achievements is
+// only their hint.
+func (a Achievement) lockedText(locale string, id int) (string, string) {
+	if !a.Secret {
+		return a.localized(locale, id)
+	}
+
+	hint := a.Hint
+	if message, ok := Messages.Lookup(locale, fmt.Sprintf("achievement.%d.hint", id)); ok {
+		hint = message.Text
+	}
+	if hint == "" {
+		hint = tr(locale, "achievement.secretHint")
+	}
+
+	return tr(locale, "achievement.secretName"), hint
+}
+
+// Checks the achievements of a game in the background, so clicks never wait on them. Snapshots
+// of the game are queued as it is played, and the achievements interested in each are checked
+// in order until the game ends.
+type achievementTracker struct {
+	mutex    sync.Mutex
+	closed   bool
+	queue    chan AchievementSnapshot
+	done     chan struct{}
+	unlocked map[int]Achievement
+}
+
+func newAchievementTracker() *achievementTracker {
+	tracker := &achievementTracker{
+		queue:    make(chan AchievementSnapshot, 64),
+		done:     make(chan struct{}),
+		unlocked: make(map[int]Achievement),
+	}
+	go tracker.run()
+
+	return tracker
+}
+
+func (t *achievementTracker) run() {
+	defer close(t.done)
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+
+	for data := range t.queue {
+		for _, achievement := range achievementsByKind[data.Kind] {
+			if _, ok := t.unlocked[achievement.ID]; ok {
+				continue
 			}
-			gameDuration := time.Since(data.Game.StartTime)
-			return gameDuration.Seconds() <= 10
-		},
-	},
-	19: {
-		Name:        "Are you a snail?",
-		Description: "I don't understand how it could possibly take you this long",
-		CheckFunc: func(data CheckData) bool {
-			if data.Event != minesweeper.Won {
-				return false
+			if achievement.check(data) {
+				t.unlocked[achievement.ID] = achievement
 			}
-			gameDuration := time.Since(data.Game.StartTime)
-			return gameDuration.Seconds() >= 60*25
-		},
-	},
+		}
+	}
+}
+
+func (t *achievementTracker) push(data AchievementSnapshot) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	// Clicks racing the end of the game come too late to count.
+	if t.closed {
+		return
+	}
+
+	t.queue <- data
+}
+
+// Queues the last snapshot of the game, then waits for every snapshot to be checked and gets
+// the achievements unlocked in the game.
+func (t *achievementTracker) finish(data AchievementSnapshot) map[int]Achievement {
+	t.push(data)
+
+	t.mutex.Lock()
+	if !t.closed {
+		t.closed = true
+		close(t.queue)
+	}
+	t.mutex.Unlock()
+	<-t.done
+
+	return t.unlocked
+}
+
+// Copies what achievements need to know out of the game.
+func (game *MinesweeperGame) achievementSnapshot(kind, event int, spot *minesweeper.Spot, chorded, flagged bool) AchievementSnapshot {
+	data := AchievementSnapshot{
+		Kind:       kind,
+		Event:      event,
+		Difficulty: game.Difficulty,
+		Flags:      game.Flags,
+		Started:    !game.StartTime.IsZero(),
+		Flagged:    flagged,
+		Chorded:    chorded,
+	}
+	if data.Started {
+		data.Duration = time.Since(game.StartTime)
+	}
+	if spot != nil {
+		data.Cell = &CellSnapshot{
+			Type:          spot.Type,
+			DisplayedType: spot.DisplayedType,
+			Blind:         blindSpot(spot),
+			Obvious:       spot.Type == minesweeper.Bomb && obviousBomb(game.Game, spot),
+		}
+	}
+
+	return data
 }
 
-func AwardAchievements(game *MinesweeperGame, event int, clickedCell *minesweeper.Spot, chord, flagged, beforeVisit bool) map[int]Achievement {
-	var achievementsGotten = make(map[int]Achievement)
+// Queues a moment of the game for its achievements to be checked against. Custom games don't
+// have achievements.
+func (game *MinesweeperGame) queueAchievements(kind, event int, spot *minesweeper.Spot, chorded, flagged bool) {
+	if game.Achievements == nil {
+		return
+	}
+
+	game.Achievements.push(game.achievementSnapshot(kind, event, spot, chorded, flagged))
+}
 
-	if game.Difficulty == "custom" {
-		return achievementsGotten
+// Checks the achievements of the game once it has ended and the stats of the player are
+// updated, and stores the progress towards the ones counting stats. Gets every achievement
+// unlocked in the game.
+func finishAchievements(game *MinesweeperGame, event int, duration time.Duration, userData *UserData) map[int]Achievement {
+	if game.Achievements == nil {
+		return nil
 	}
 
-	data := CheckData{
-		Event:       event,
-		Game:        game,
-		ClickedCell: clickedCell,
-		Flagged:     flagged,
-		Chorded:     chord,
-		PreVisit:    beforeVisit,
+	data := game.achievementSnapshot(AchievementEnd, event, nil, false, false)
+	data.Duration = duration
+	data.Stats = userData.Difficulties
+
+	if userData.AchievementProgress == nil {
+		userData.AchievementProgress = make(map[string]int64)
 	}
+	for ID, achievement := range Achievements {
+		if counter, ok := achievement.counter(); ok {
+			userData.AchievementProgress[strconv.Itoa(ID)] = achievement.progress(counter, userData.Difficulties)
+		}
+	}
+
+	return game.Achievements.finish(data)
+}
+
+func (u UserData) hasAchievement(ID int) bool {
+	return u.achievementIndex(ID) >= 0
+}
 
-	for ID, achievment := range Achievements {
-		if achievment.CheckFunc(data) {
-			achievementsGotten[ID] = achievment
+func (u UserData) achievementIndex(ID int) int {
+	for index, unlock := range u.Achievements {
+		if unlock.ID == ID {
+			return index
 		}
 	}
 
-	return achievementsGotten
+	return -1
 }
 
-func getFieldsAndComponents(userData UserData, page int) ([]*discordgo.MessageEmbedField, []discordgo.MessageComponent) {
+// Records an achievement unlocked in a game, or raises the tier of one unlocked before. Gets
+// whether the unlock is new.
+func (u *UserData) unlockAchievement(achievement Achievement, game *MinesweeperGame, at time.Time) bool {
+	tier := achievement.tier(game.Difficulty)
+	if index := u.achievementIndex(achievement.ID); index >= 0 {
+		if tierRank(tier) <= tierRank(u.Achievements[index].Tier) {
+			return false
+		}
+		u.Achievements[index].Tier = tier
+		return true
+	}
+
+	u.Achievements = append(u.Achievements, AchievementUnlock{
+		ID:         achievement.ID,
+		UnlockedAt: at,
+		GuildID:    game.GuildID,
+		Tier:       tier,
+	})
+
+	return true
+}
+
+// Counts the unlocked tiered achievements by tier.
+func (u UserData) badgeCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, unlock := range u.Achievements {
+		if unlock.Tier != "" {
+			counts[unlock.Tier]++
+		}
+	}
+
+	return counts
+}
+
+// Writes out the badges of the user, the highest tier first.
+func badgeSummary(locale string, userData UserData) string {
+	counts := userData.badgeCounts()
+
+	var parts []string
+	for index := len(AchievementTiers) - 1; index >= 0; index-- {
+		tier := AchievementTiers[index]
+		parts = append(parts, tr(locale, "profile.badgeCount", tier.Badge, counts[tier.Name]))
+	}
+
+	return strings.Join(parts, "  ")
+}
+
+// Share of players that unlocked each achievement in percent, recomputed at most every ten minutes.
+var achievementRarity = struct {
+	sync.Mutex
+	percentages map[int]float64
+	updatedAt   time.Time
+}{}
+
+func getAchievementRarity() map[int]float64 {
+	achievementRarity.Lock()
+	defer achievementRarity.Unlock()
+
+	if achievementRarity.percentages != nil && time.Since(achievementRarity.updatedAt) < 10*time.Minute {
+		return achievementRarity.percentages
+	}
+
+	percentages, err := computeAchievementRarity()
+	if err != nil {
+		fmt.Println(err)
+		return achievementRarity.percentages
+	}
+	achievementRarity.percentages = percentages
+	achievementRarity.updatedAt = time.Now()
+
+	return percentages
+}
+
+// Counts the unlocks of every achievement against the amount of players that have played a game.
+func computeAchievementRarity() (map[int]float64, error) {
+	pipeline := bson.A{
+		bson.D{{Key: "$facet", Value: bson.D{
+			{Key: "players", Value: bson.A{
+				bson.D{{Key: "$match", Value: bson.D{{Key: "difficulties", Value: bson.D{{Key: "$exists", Value: true}}}}}},
+				bson.D{{Key: "$count", Value: "count"}},
+			}},
+			{Key: "unlocks", Value: bson.A{
+				bson.D{{Key: "$unwind", Value: "$achievements"}},
+				bson.D{{Key: "$group", Value: bson.D{
+					// Unlocks from before timestamps were stored are only the ID.
+					{Key: "_id", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$achievements.id", "$achievements"}}}},
+					{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+				}}},
+			}},
+		}}},
+	}
+
+	cursor, err := d.Collection("userdata").Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Players []struct {
+			Count int64 `bson:"count"`
+		} `bson:"players"`
+		Unlocks []struct {
+			ID    int   `bson:"_id"`
+			Count int64 `bson:"count"`
+		} `bson:"unlocks"`
+	}
+	if err := cursor.All(context.TODO(), &results); err != nil {
+		return nil, err
+	}
+
+	percentages := make(map[int]float64)
+	if len(results) == 0 || len(results[0].Players) == 0 || results[0].Players[0].Count == 0 {
+		return percentages, nil
+	}
+
+	players := float64(results[0].Players[0].Count)
+	for ID := range Achievements {
+		percentages[ID] = 0
+	}
+	for _, unlock := range results[0].Unlocks {
+		percentages[unlock.ID] = math.Min(float64(unlock.Count)/players*100, 100)
+	}
+
+	return percentages, nil
+}
+
+// Gets the points an achievement is worth on the achievement score leaderboard, the share of
+// players that have not unlocked it, but at least one.
+func achievementPoints(percentage float64) int64 {
+	return int64(math.Max(math.Round(100-percentage), 1))
+}
+
+// Draws the progress towards a target as a bar.
+func progressBar(locale string, value, target int64) string {
+	const width = 10
+	filled := int(value * width / target)
+	if filled > width {
+		filled = width
+	}
+
+	bar := strings.Repeat("▰", filled) + strings.Repeat("▱", width-filled)
+	return tr(locale, "profile.progress", bar, formatNumber(value), formatNumber(target))
+}
+
+func getFieldsAndComponents(locale string, userData UserData, page int) ([]*discordgo.MessageEmbedField, []discordgo.MessageComponent) {
 	var components []discordgo.MessageComponent
 	var fields []*discordgo.MessageEmbedField
 
-	pages := math.Ceil(float64(len(userData.Achievements)) / float64(5))
+	// Unlocked achievements come first in the order they were unlocked, then the locked ones.
+	unlocks := make(map[int]AchievementUnlock)
+	var ordered []int
+	for _, unlock := range userData.Achievements {
+		unlocks[unlock.ID] = unlock
+		ordered = append(ordered, unlock.ID)
+	}
+	for _, ID := range AchievementOrder {
+		if _, ok := unlocks[ID]; !ok {
+			ordered = append(ordered, ID)
+		}
+	}
+	rarity := getAchievementRarity()
+
+	pages := math.Ceil(float64(len(ordered)) / float64(5))
 
 	components = append(components, discordgo.ActionsRow{
 		Components: []discordgo.MessageComponent{
@@ -350,18 +820,52 @@ func getFieldsAndComponents(userData UserData, page int) ([]*discordgo.MessageEm
 		},
 	})
 
-	paginated := paginate(userData.Achievements, page, 5)
+	paginated := paginate(ordered, page, 5)
 	for _, ID := range paginated {
-		achievement := Achievements[ID]
+		achievement, ok := Achievements[ID]
+		if !ok {
+			continue
+		}
+		unlock, unlocked := unlocks[ID]
+		name, description := achievement.localized(locale, ID)
+		if !unlocked {
+			name, description = achievement.lockedText(locale, ID)
+		}
+		if percentage, ok := rarity[ID]; ok {
+			description += "\n" + tr(locale, "profile.rarity", percentage)
+		}
+		if unlocked {
+			marker := "✅"
+			if badge := tierBadge(unlock.Tier); badge != "" {
+				marker = badge
+				description += "\n" + tr(locale, "profile.tier", tr(locale, "achievement.tier."+unlock.Tier))
+			}
+			// Unlocks from before timestamps were stored have none to show.
+			if !unlock.UnlockedAt.IsZero() {
+				description += "\n" + tr(locale, "profile.unlockedAt", unlock.UnlockedAt.Unix())
+			}
+			fields = append(fields, &discordgo.MessageEmbedField{
+				Name:  marker + " " + name,
+				Value: description,
+			})
+			continue
+		}
+
+		if achievement.Tiered {
+			description += "\n" + tr(locale, "profile.tiers")
+		}
+		if counter, ok := achievement.counter(); ok && !achievement.Secret {
+			description += "\n" + progressBar(locale, userData.AchievementProgress[strconv.Itoa(ID)], counter.Target)
+		}
 		fields = append(fields, &discordgo.MessageEmbedField{
-			Name:  achievement.Name,
-			Value: achievement.Description,
+			Name:  "🔒 " + name,
+			Value: description,
 		})
 	}
 
-	if len(userData.Achievements) <= 0 {
+	if len(ordered) <= 0 {
 		fields = append(fields, &discordgo.MessageEmbedField{
-			Name: "No unlocked achievements",
+			Name: tr(locale, "profile.noAchievements"),
 		})
 	}
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"main/minesweeper"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"go.mongodb.org/mongo-driver/bson"
@@ -113,18 +114,94 @@ func AdminCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			difficulty = minesweeper.Hard
 		}
 
-		addLeaderboardMessage(match[1], match[2], match[3], difficulty)
+		period := PeriodAllTime
+		if v, ok := optionMap["period"]; ok {
+			period = v.StringValue()
+		}
+
+		addLeaderboardMessage(match[1], match[2], match[3], difficulty, period)
 
-		content := fmt.Sprintf("Added %s to automatic editing for difficulty **%s**!", optionMap["message"].StringValue(), optionMap["difficulty"].StringValue())
+		content := fmt.Sprintf("Added %s to automatic editing for difficulty **%s** and period **%s**!", optionMap["message"].StringValue(), optionMap["difficulty"].StringValue(), period)
 		if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 			Content: &content,
 		}); err != nil {
 			cmdError(s, i, err)
 		}
 
+	case "season":
+		name := optionMap["name"].StringValue()
+		start, err := time.Parse("2006-01-02", optionMap["start"].StringValue())
+		if err != nil {
+			cmdError(s, i, err)
+			return
+		}
+		end, err := time.Parse("2006-01-02", optionMap["end"].StringValue())
+		if err != nil {
+			cmdError(s, i, err)
+			return
+		}
+
+		if err := setSeason(name, start, end); err != nil {
+			cmdError(s, i, err)
+			return
+		}
+
+		replyContent := fmt.Sprintf("Season **%s** runs from <t:%d:D> until <t:%d:D>", name, start.Unix(), end.Unix())
+		if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Flags:   1 << 6,
+				Content: replyContent,
+			},
+		}); err != nil {
+			cmdError(s, i, err)
+		}
+
+	case "preset":
+		preset := CustomPreset{
+			Name:             optionMap["name"].StringValue(),
+			Bombs:            clampBombs(optionMap["bombs"].IntValue()),
+			SurroundingBombs: optionMap["surroundingbombs"].BoolValue(),
+			NoStartSpot:      optionMap["nostartspot"].BoolValue(),
+			CreatedBy:        userID,
+		}
+
+		replyContent := fmt.Sprintf("Saved preset **%s** (%s)", preset.Name, preset.settingsString(Messages.Fallback))
+		if err := setPreset(preset); err != nil {
+			replyContent = fmt.Sprintf("Failed to save preset: %s", err)
+		}
+
+		if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Flags:   1 << 6,
+				Content: replyContent,
+			},
+		}); err != nil {
+			cmdError(s, i, err)
+		}
+
+	case "deletepreset":
+		name := optionMap["name"].StringValue()
+
+		replyContent := fmt.Sprintf("Deleted preset **%s**, its leaderboard is kept in case it is recreated", name)
+		if !deletePreset(name) {
+			replyContent = fmt.Sprintf("There is no preset named **%s**", name)
+		}
+
+		if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Flags:   1 << 6,
+				Content: replyContent,
+			},
+		}); err != nil {
+			cmdError(s, i, err)
+		}
+
 	case "win":
 		target := optionMap["target"].UserValue(s).ID
-		game, ok := Games[target]
+		game, ok := getGame(target)
 		// Check if the user has a game open.
 		if !ok {
 			if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -153,9 +230,35 @@ func AdminCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 		HandleGameEnd(s, game, minesweeper.Won, false)
 
+	case "coins":
+		target := optionMap["target"].UserValue(s).ID
+		amount := optionMap["amount"].IntValue()
+		action := "Granted"
+		if optionMap["action"].StringValue() == "revoke" {
+			amount = -amount
+			action = "Revoked"
+		}
+
+		balance, err := addCoins(target, amount)
+		if err != nil {
+			cmdError(s, i, err)
+			return
+		}
+
+		replyContent := fmt.Sprintf("%s %d coins for `%s`, their balance is now %d", action, optionMap["amount"].IntValue(), target, balance)
+		if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Flags:   1 << 6,
+				Content: replyContent,
+			},
+		}); err != nil {
+			cmdError(s, i, err)
+		}
+
 	case "reveal":
 		target := optionMap["target"].UserValue(s).ID
-		game, ok := Games[target]
+		game, ok := getGame(target)
 		// Check if the user has a game open.
 		if !ok {
 			if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
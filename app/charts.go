@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Chart types selectable in /stats chart.
+const (
+	ChartTime     = "time"
+	ChartOutcomes = "outcomes"
+)
+
+const (
+	chartWidth  = 800
+	chartHeight = 400
+	chartMargin = 50
+)
+
+// Amount of wins the rolling average line of the time chart is taken over.
+var chartAverageWindow = 5
+
+var (
+	chartBackground = color.RGBA{0x2b, 0x2d, 0x31, 0xff}
+	chartGrid       = color.RGBA{0x40, 0x42, 0x49, 0xff}
+	chartText       = color.RGBA{0xdb, 0xde, 0xe1, 0xff}
+	chartLine       = color.RGBA{0x58, 0x65, 0xf2, 0xff}
+	chartAverage    = color.RGBA{0xfe, 0xe7, 0x5c, 0xff}
+)
+
+var outcomeColors = map[string]color.RGBA{
+	OutcomeWon:      {0x57, 0xf2, 0x87, 0xff},
+	OutcomeLost:     {0xed, 0x42, 0x45, 0xff},
+	OutcomeGaveUp:   {0x94, 0x9b, 0xa4, 0xff},
+	OutcomeTimedOut: {0xfe, 0xe7, 0x5c, 0xff},
+}
+
+var chartOutcomes = []string{OutcomeWon, OutcomeLost, OutcomeGaveUp, OutcomeTimedOut}
+
+type chart struct {
+	img *image.RGBA
+}
+
+func newChart(title string) *chart {
+	c := &chart{img: image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))}
+	draw.Draw(c.img, c.img.Bounds(), &image.Uniform{chartBackground}, image.Point{}, draw.Src)
+	c.text(chartMargin, chartMargin/2, title, chartText)
+
+	return c
+}
+
+// Area of the image the data is plotted in.
+func (c *chart) plot() image.Rectangle {
+	return image.Rect(chartMargin, chartMargin, chartWidth-chartMargin/2, chartHeight-chartMargin)
+}
+
+func (c *chart) text(x, y int, text string, col color.Color) {
+	drawer := &font.Drawer{
+		Dst:  c.img,
+		Src:  &image.Uniform{col},
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(text)
+}
+
+// Draws text with its right edge at x.
+func (c *chart) textRight(x, y int, text string, col color.Color) {
+	width := font.MeasureString(basicfont.Face7x13, text).Ceil()
+	c.text(x-width, y, text, col)
+}
+
+func (c *chart) rect(r image.Rectangle, col color.Color) {
+	draw.Draw(c.img, r, &image.Uniform{col}, image.Point{}, draw.Src)
+}
+
+// Draws a line two pixels wide.
+func (c *chart) line(x0, y0, x1, y1 int, col color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	err := dx + dy
+	for {
+		c.rect(image.Rect(x0, y0, x0+2, y0+2), col)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// Draws horizontal grid lines with labels for values between 0 and max.
+func (c *chart) grid(max float64, label func(float64) string) {
+	plot := c.plot()
+	for step := 0; step <= 4; step++ {
+		value := max * float64(step) / 4
+		y := plot.Max.Y - int(float64(plot.Dy())*float64(step)/4)
+		c.rect(image.Rect(plot.Min.X, y, plot.Max.X, y+1), chartGrid)
+		c.textRight(plot.Min.X-6, y+4, label(value), chartText)
+	}
+}
+
+func (c *chart) encode() (*bytes.Buffer, error) {
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, c.img); err != nil {
+		return nil, err
+	}
+
+	return &buffer, nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// Renders the time of every win among the records, ordered newest first, along with a rolling average.
+// The labels are in the language of the locale.
+func renderTimeChart(locale, title string, records []GameRecord) (*bytes.Buffer, error) {
+	var times []float64
+	for index := len(records) - 1; index >= 0; index-- {
+		if records[index].Outcome == OutcomeWon {
+			times = append(times, records[index].Duration)
+		}
+	}
+
+	c := newChart(title)
+	plot := c.plot()
+	if len(times) == 0 {
+		c.text(plot.Min.X, plot.Min.Y+plot.Dy()/2, tr(locale, "chart.noWins"), chartText)
+		return c.encode()
+	}
+
+	var max float64
+	for _, t := range times {
+		if t > max {
+			max = t
+		}
+	}
+	c.grid(max, func(value float64) string {
+		return fmt.Sprintf("%.1fs", value)
+	})
+
+	point := func(index int, value float64) (int, int) {
+		x := plot.Min.X
+		if len(times) > 1 {
+			x += plot.Dx() * index / (len(times) - 1)
+		}
+		y := plot.Max.Y - int(float64(plot.Dy())*value/max)
+		return x, y
+	}
+
+	averages := make([]float64, len(times))
+	var sum float64
+	for index, t := range times {
+		sum += t
+		if index >= chartAverageWindow {
+			sum -= times[index-chartAverageWindow]
+		}
+		count := index + 1
+		if count > chartAverageWindow {
+			count = chartAverageWindow
+		}
+		averages[index] = sum / float64(count)
+	}
+
+	for index := range times {
+		x, y := point(index, times[index])
+		c.rect(image.Rect(x-2, y-2, x+3, y+3), chartLine)
+		if index == 0 {
+			continue
+		}
+		px, py := point(index-1, times[index-1])
+		c.line(px, py, x, y, chartLine)
+		ax0, ay0 := point(index-1, averages[index-1])
+		ax1, ay1 := point(index, averages[index])
+		c.line(ax0, ay0, ax1, ay1, chartAverage)
+	}
+
+	c.text(plot.Min.X, chartHeight-chartMargin/2, tr(locale, "chart.oldest"), chartText)
+	c.textRight(plot.Max.X, chartHeight-chartMargin/2, tr(locale, "chart.newest"), chartText)
+	c.textRight(plot.Max.X, chartMargin/2, trPlural(locale, "chart.average", float64(len(times)), len(times), chartAverageWindow), chartAverage)
+
+	return c.encode()
+}
+
+// Renders a bar for every outcome of every difficulty, with the labels in the language of the locale.
+func renderOutcomeChart(locale, title string, difficulties []string, counts map[string]map[string]int64) (*bytes.Buffer, error) {
+	c := newChart(title)
+	plot := c.plot()
+
+	var max int64
+	for _, outcomes := range counts {
+		for _, count := range outcomes {
+			if count > max {
+				max = count
+			}
+		}
+	}
+	if max == 0 {
+		c.text(plot.Min.X, plot.Min.Y+plot.Dy()/2, tr(locale, "chart.noGames"), chartText)
+		return c.encode()
+	}
+
+	c.grid(float64(max), func(value float64) string {
+		return formatNumber(int64(value))
+	})
+
+	groupWidth := plot.Dx() / len(difficulties)
+	barWidth := groupWidth / (len(chartOutcomes) + 1)
+	for group, difficulty := range difficulties {
+		groupX := plot.Min.X + group*groupWidth + barWidth/2
+		for index, outcome := range chartOutcomes {
+			count := counts[difficulty][outcome]
+			height := int(float64(plot.Dy()) * float64(count) / float64(max))
+			x := groupX + index*barWidth
+			c.rect(image.Rect(x+2, plot.Max.Y-height, x+barWidth-2, plot.Max.Y), outcomeColors[outcome])
+		}
+		c.text(groupX, chartHeight-chartMargin/2, strings.ToUpper(difficulty), chartText)
+	}
+
+	legendX := plot.Max.X
+	for index := len(chartOutcomes) - 1; index >= 0; index-- {
+		outcome := chartOutcomes[index]
+		label := tr(locale, "chart.outcome."+outcome)
+		width := font.MeasureString(basicfont.Face7x13, label).Ceil()
+		legendX -= width
+		c.text(legendX, chartMargin/2, label, outcomeColors[outcome])
+		legendX -= 12
+	}
+
+	return c.encode()
+}
@@ -14,6 +14,274 @@ var Commands = []*discordgo.ApplicationCommand{
 	{
 		Name:        "minesweeper",
 		Description: "Generate a 5x5 minesweeper board",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "difficulty",
+				Description: "Difficulty level, defaults to the one in your settings",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{
+						Name:  "Easy",
+						Value: "easy",
+					},
+					{
+						Name:  "Medium",
+						Value: "medium",
+					},
+					{
+						Name:  "Hard",
+						Value: "hard",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "style",
+				Description: "How the board is played",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{
+						Name:  "Buttons",
+						Value: "buttons",
+					},
+					{
+						Name:  "Spoiler tags",
+						Value: "spoiler",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "theme",
+				Description: "Look of the board",
+				Required:    false,
+			},
+		},
+	},
+	{
+		Name:        "custom",
+		Description: "Generate a custom minesweeper game",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "bombs",
+				Description: "Bomb count",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Required:    true,
+			},
+			{
+				Name:        "surroundingbombs",
+				Description: "Allow bombs to be placed surrounding the start spot. Ignored if nostartspot enabled.",
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Required:    true,
+			},
+			{
+				Name:        "nostartspot",
+				Description: "Allow the user to start game in any spot",
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Required:    true,
+			},
+			{
+				Name:        "theme",
+				Description: "Look of the board",
+				Type:        discordgo.ApplicationCommandOptionString,
+				Required:    false,
+			},
+		},
+	},
+	{
+		Name:        "preset",
+		Description: "Play and compete on named custom game presets",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "play",
+				Description: "Play a custom game preset",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Name of the preset",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List all custom game presets",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "leaderboard",
+				Description: "Gets the leaderboard of a preset for the current server, or global.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Name of the preset",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "global",
+						Description: "Get the global leaderboard",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "period",
+						Description: "Time period of the leaderboard",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "All time",
+								Value: "alltime",
+							},
+							{
+								Name:  "Weekly",
+								Value: "weekly",
+							},
+							{
+								Name:  "Monthly",
+								Value: "monthly",
+							},
+							{
+								Name:  "Season",
+								Value: "season",
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        "leaderboard",
+		Description: "Gets the leaderboard for the current server, or global.",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "difficulty",
+				Description: "Difficulty level",
+				Required:    true,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{
+						Name:  "Easy",
+						Value: "easy",
+					},
+					{
+						Name:  "Medium",
+						Value: "medium",
+					},
+					{
+						Name:  "Hard",
+						Value: "hard",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "global",
+				Description: "Get the global leaderboard",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "scope",
+				Description: "Who to rank, defaults to this server",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{
+						Name:  "Server",
+						Value: "server",
+					},
+					{
+						Name:  "Global",
+						Value: "global",
+					},
+					{
+						Name:  "Friends",
+						Value: "friends",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "metric",
+				Description: "What to rank players by",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{
+						Name:  "Fastest time",
+						Value: "time",
+					},
+					{
+						Name:  "Total wins",
+						Value: "wins",
+					},
+					{
+						Name:  "Win rate",
+						Value: "winrate",
+					},
+					{
+						Name:  "Current win streak",
+						Value: "streak",
+					},
+					{
+						Name:  "Best win streak",
+						Value: "beststreak",
+					},
+					{
+						Name:  "Achievements",
+						Value: "achievements",
+					},
+					{
+						Name:  "Achievement score",
+						Value: "achievementscore",
+					},
+					{
+						Name:  "Skill rating",
+						Value: "rating",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "period",
+				Description: "Time period of the leaderboard",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{
+						Name:  "All time",
+						Value: "alltime",
+					},
+					{
+						Name:  "Weekly",
+						Value: "weekly",
+					},
+					{
+						Name:  "Monthly",
+						Value: "monthly",
+					},
+					{
+						Name:  "Season",
+						Value: "season",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "season",
+				Description: "Name of a season to show, seasons that ended show their final standings",
+				Required:    false,
+				MaxLength:   maxSeasonName,
+			},
+		},
+	},
+	{
+		Name:        "matchmake",
+		Description: "Race against a player with a similar rating in this server",
 		Options: []*discordgo.ApplicationCommandOption{
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
@@ -26,70 +294,542 @@ var Commands = []*discordgo.ApplicationCommand{
 						Value: "easy",
 					},
 					{
-						Name:  "Medium",
-						Value: "medium",
+						Name:  "Medium",
+						Value: "medium",
+					},
+					{
+						Name:  "Hard",
+						Value: "hard",
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        "stats",
+		Description: "Look back at your finished games",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "history",
+				Description: "Recent games and averages over time",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "difficulty",
+						Description: "Only show games of this difficulty",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "Easy",
+								Value: "easy",
+							},
+							{
+								Name:  "Medium",
+								Value: "medium",
+							},
+							{
+								Name:  "Hard",
+								Value: "hard",
+							},
+							{
+								Name:  "Custom",
+								Value: "custom",
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Name:        "target",
+						Description: "User to get the history of",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "chart",
+				Description: "Render a chart of your games",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "type",
+						Description: "What to chart",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "Time per win",
+								Value: "time",
+							},
+							{
+								Name:  "Outcomes by difficulty",
+								Value: "outcomes",
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "games",
+						Description: "Amount of recent games the time chart covers",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "difficulty",
+						Description: "Only chart games of this difficulty",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "Easy",
+								Value: "easy",
+							},
+							{
+								Name:  "Medium",
+								Value: "medium",
+							},
+							{
+								Name:  "Hard",
+								Value: "hard",
+							},
+							{
+								Name:  "Custom",
+								Value: "custom",
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Name:        "target",
+						Description: "User to get the chart of",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "export",
+				Description: "Download every game you have played",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "format",
+						Description: "File format of the export",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "CSV",
+								Value: "csv",
+							},
+							{
+								Name:  "JSON",
+								Value: "json",
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "difficulty",
+						Description: "Only export games of this difficulty",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "Easy",
+								Value: "easy",
+							},
+							{
+								Name:  "Medium",
+								Value: "medium",
+							},
+							{
+								Name:  "Hard",
+								Value: "hard",
+							},
+							{
+								Name:  "Custom",
+								Value: "custom",
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:        "settings",
+		Description: "Change your preferences",
+	},
+	{
+		Name:        "spectate",
+		Description: "Watch the game a user is playing",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user",
+				Description: "User to watch",
+				Required:    true,
+			},
+		},
+	},
+	{
+		Name:        "friends",
+		Description: "Manage the friends you are ranked against",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Add a friend",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "user",
+						Description: "User to add",
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Remove a friend",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "user",
+						Description: "User to remove",
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "Shows your friends and their personal bests",
+			},
+		},
+	},
+	{
+		Name:        "subscribe",
+		Description: "Get a direct message when someone beats your personal best",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Subscribe to the personal bests of a user",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "user",
+						Description: "User to subscribe to",
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Required:    true,
 					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Unsubscribe from the personal bests of a user",
+				Options: []*discordgo.ApplicationCommandOption{
 					{
-						Name:  "Hard",
-						Value: "hard",
+						Name:        "user",
+						Description: "User to unsubscribe from",
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Required:    true,
 					},
 				},
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "Shows the users you are subscribed to",
+			},
 		},
 	},
 	{
-		Name:        "custom",
-		Description: "Generate a custom minesweeper game",
+		Name:        "shop",
+		Description: "Spend the coins you won on cosmetics",
 		Options: []*discordgo.ApplicationCommandOption{
 			{
-				Name:        "bombs",
-				Description: "Bomb count",
-				Type:        discordgo.ApplicationCommandOptionInteger,
-				Required:    true,
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "view",
+				Description: "Shows your coins and the items for sale",
 			},
 			{
-				Name:        "surroundingbombs",
-				Description: "Allow bombs to be placed surrounding the start spot. Ignored if nostartspot enabled.",
-				Type:        discordgo.ApplicationCommandOptionBoolean,
-				Required:    true,
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "buy",
+				Description: "Buy an item",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "item",
+						Description: "Item to buy",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+					},
+				},
 			},
 			{
-				Name:        "nostartspot",
-				Description: "Allow the user to start game in any spot",
-				Type:        discordgo.ApplicationCommandOptionBoolean,
-				Required:    true,
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "equip",
+				Description: "Use an item you own",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "item",
+						Description: "Item to use",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "unequip",
+				Description: "Stop using your badge or end-game message",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "kind",
+						Description: "Kind of item to stop using",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "Badge",
+								Value: "badge",
+							},
+							{
+								Name:  "End-game message",
+								Value: "endMessage",
+							},
+						},
+					},
+				},
 			},
 		},
 	},
 	{
-		Name:        "leaderboard",
-		Description: "Gets the leaderboard for the current server, or global.",
+		Name:                     "config",
+		Description:              "Configure the bot for this server",
+		DefaultMemberPermissions: &manageServerPermission,
+		DMPermission:             &dmPermission,
 		Options: []*discordgo.ApplicationCommandOption{
 			{
-				Type:        discordgo.ApplicationCommandOptionString,
-				Name:        "difficulty",
-				Description: "Difficulty level",
-				Required:    true,
-				Choices: []*discordgo.ApplicationCommandOptionChoice{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "view",
+				Description: "Shows the configuration of this server",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "channel",
+				Description: "Allows or denies games in a channel, if any channel is allowed all others are denied",
+				Options: []*discordgo.ApplicationCommandOption{
 					{
-						Name:  "Easy",
-						Value: "easy",
+						Name:         "channel",
+						Description:  "Channel to change",
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+						Required:     true,
 					},
 					{
-						Name:  "Medium",
-						Value: "medium",
+						Name:        "access",
+						Description: "Whether games can be played in the channel",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "Allow",
+								Value: ChannelAllow,
+							},
+							{
+								Name:  "Deny",
+								Value: ChannelDeny,
+							},
+							{
+								Name:  "Default",
+								Value: ChannelDefault,
+							},
+						},
 					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "threads",
+				Description: "Plays every game in its own thread, archived when the game ends",
+				Options: []*discordgo.ApplicationCommandOption{
 					{
-						Name:  "Hard",
-						Value: "hard",
+						Name:        "enabled",
+						Description: "Whether games get their own thread",
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Required:    true,
 					},
 				},
 			},
 			{
-				Type:        discordgo.ApplicationCommandOptionBoolean,
-				Name:        "global",
-				Description: "Get the global leaderboard",
-				Required:    false,
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "timeout",
+				Description: "Sets after how many seconds games end automatically",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "seconds",
+						Description: "Seconds until games end, 0 uses the bot default",
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "sarcasm",
+				Description: "Turns the sarcastic game end messages on or off",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "enabled",
+						Description: "Whether sarcastic messages are used",
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "theme",
+				Description: "Sets the theme games use when the player hasn't picked one",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "theme",
+						Description: "Theme to use, leave empty for the bot default",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "leaderboard",
+				Description: "Sets who can see the leaderboard of this server",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "visibility",
+						Description: "Visibility of the leaderboard",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "Public",
+								Value: LeaderboardPublic,
+							},
+							{
+								Name:  "Private, only the user asking sees it",
+								Value: LeaderboardPrivate,
+							},
+							{
+								Name:  "Hidden",
+								Value: LeaderboardHidden,
+							},
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "modrole",
+				Description: "Sets the role that can end the games of others",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "role",
+						Description: "Role to use, leave empty to remove it",
+						Type:        discordgo.ApplicationCommandOptionRole,
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "reward",
+				Description: "Gives roles to members for their achievements, wins or leaderboard spots",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "add",
+						Description: "Gives a role to members who earn it, replacing the reward of the role",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Name:        "role",
+								Description: "Role to give out",
+								Type:        discordgo.ApplicationCommandOptionRole,
+								Required:    true,
+							},
+							{
+								Name:        "type",
+								Description: "What earns the role",
+								Type:        discordgo.ApplicationCommandOptionString,
+								Required:    true,
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{
+										Name:  "Unlocking an achievement",
+										Value: RewardAchievement,
+									},
+									{
+										Name:  "Reaching a win count",
+										Value: RewardWins,
+									},
+									{
+										Name:  "Holding a top 3 time on the server leaderboard",
+										Value: RewardTopThree,
+									},
+								},
+							},
+							{
+								Name:        "achievement",
+								Description: "ID of the achievement, for achievement rewards",
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Required:    false,
+							},
+							{
+								Name:        "wins",
+								Description: "Wins needed, for win rewards",
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Required:    false,
+							},
+							{
+								Name:        "difficulty",
+								Description: "Difficulty the wins or leaderboard count on, wins count on all if empty",
+								Type:        discordgo.ApplicationCommandOptionString,
+								Required:    false,
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{
+										Name:  "Easy",
+										Value: "easy",
+									},
+									{
+										Name:  "Medium",
+										Value: "medium",
+									},
+									{
+										Name:  "Hard",
+										Value: "hard",
+									},
+								},
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "remove",
+						Description: "Stops giving out a role, members keep it",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Name:        "role",
+								Description: "Role to stop giving out",
+								Type:        discordgo.ApplicationCommandOptionRole,
+								Required:    true,
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "log",
+						Description: "Shows the latest roles given out or taken",
+					},
+				},
 			},
 		},
 	},
@@ -178,6 +918,101 @@ var Commands = []*discordgo.ApplicationCommand{
 							},
 						},
 					},
+					{
+						Name:        "period",
+						Description: "Time period to use for leaderboard",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "All time",
+								Value: "alltime",
+							},
+							{
+								Name:  "Weekly",
+								Value: "weekly",
+							},
+							{
+								Name:  "Monthly",
+								Value: "monthly",
+							},
+							{
+								Name:  "Season",
+								Value: "season",
+							},
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "season",
+				Description: "Create or change a leaderboard season",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "name",
+						Description: "Name of the season",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+						MaxLength:   maxSeasonName,
+					},
+					{
+						Name:        "start",
+						Description: "Start date of the season in UTC, formatted as YYYY-MM-DD",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+					},
+					{
+						Name:        "end",
+						Description: "End date of the season in UTC, formatted as YYYY-MM-DD",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "preset",
+				Description: "Create or rename a custom game preset",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "name",
+						Description: "Name of the preset",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+						MaxLength:   32,
+					},
+					{
+						Name:        "bombs",
+						Description: "Bomb count",
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Required:    true,
+					},
+					{
+						Name:        "surroundingbombs",
+						Description: "Allow bombs to be placed surrounding the start spot. Ignored if nostartspot enabled.",
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Required:    true,
+					},
+					{
+						Name:        "nostartspot",
+						Description: "Allow the user to start game in any spot",
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "deletepreset",
+				Description: "Delete a custom game preset",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "name",
+						Description: "Name of the preset",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+					},
 				},
 			},
 			{
@@ -193,6 +1028,42 @@ var Commands = []*discordgo.ApplicationCommand{
 					},
 				},
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "coins",
+				Description: "Grant or revoke coins of a user",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "target",
+						Description: "user to change the coins of",
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Required:    true,
+					},
+					{
+						Name:        "action",
+						Description: "Whether to grant or revoke the coins",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "Grant",
+								Value: "grant",
+							},
+							{
+								Name:  "Revoke",
+								Value: "revoke",
+							},
+						},
+					},
+					{
+						Name:        "amount",
+						Description: "Number of coins",
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Required:    true,
+						MinValue:    &minCoins,
+					},
+				},
+			},
 			{
 				Type:        discordgo.ApplicationCommandOptionSubCommand,
 				Name:        "reveal",
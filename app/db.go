@@ -4,22 +4,35 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type LeaderboardEntry struct {
+	GuildID    string    `bson:"guildID"`
+	Difficulty int       `bson:"difficulty"`
+	Preset     string    `bson:"preset"`
+	Period     string    `bson:"period"`
+	UserID     string    `bson:"userId"`
+	Time       float64   `bson:"time"`
+	AchievedAt time.Time `bson:"achievedAt"`
+}
+
+// Leaderboard entries from before they were stored as their own documents.
+type LegacyLeaderboardEntry struct {
 	UserID string  `bson:"userId"`
 	Time   float64 `bson:"time"`
 	Spot   int     `bson:"spot"`
 }
 
 type Leaderboards struct {
-	Easy   []LeaderboardEntry `bson:"easy"`
-	Medium []LeaderboardEntry `bson:"Medium"`
-	Hard   []LeaderboardEntry `bson:"hard"`
+	Easy   []LegacyLeaderboardEntry `bson:"easy"`
+	Medium []LegacyLeaderboardEntry `bson:"Medium"`
+	Hard   []LegacyLeaderboardEntry `bson:"hard"`
 }
 
 type PresenceData struct {
@@ -29,11 +42,16 @@ type PresenceData struct {
 }
 
 type DifficultyData struct {
-	Wins      int64   `bson:"wins"`
-	Losses    int64   `bson:"losses"`
-	WinStreak int64   `bson:"streak"`
-	PB        float64 `bson:"PB"`
-	PW        float64 `bson:"PW"`
+	Wins       int64   `bson:"wins"`
+	Losses     int64   `bson:"losses"`
+	WinStreak  int64   `bson:"streak"`
+	BestStreak int64   `bson:"bestStreak"`
+	PB         float64 `bson:"PB"`
+	PW         float64 `bson:"PW"`
+	Rating     float64 `bson:"rating"`
+	RatedGames int64   `bson:"ratedGames"`
+	// Lengths of broken win streaks, longest first.
+	StreakHistory []int64 `bson:"streakHistory"`
 }
 
 type DifficultiesMap struct {
@@ -44,12 +62,87 @@ type DifficultiesMap struct {
 
 type GuildData struct {
 	GuildID     string       `bson:"guildID"`
-	Leaderboard Leaderboards `bson:"timeLeaderboard"`
+	Leaderboard Leaderboards `bson:"timeLeaderboard,omitempty"`
+	Config      GuildConfig  `bson:"config"`
+}
+type GuildConfig struct {
+	AllowedChannels       []string `bson:"allowedChannels"`
+	DeniedChannels        []string `bson:"deniedChannels"`
+	GameThreads           bool     `bson:"gameThreads"`
+	EndAfter              int64    `bson:"endAfter"`
+	NoSarcasm             bool     `bson:"noSarcasm"`
+	DefaultTheme          string   `bson:"defaultTheme"`
+	LeaderboardVisibility string   `bson:"leaderboardVisibility"`
+	ModRole               string   `bson:"modRole"`
+	// Roles given to members for their achievements, wins or leaderboard spots.
+	RoleRewards []RoleReward `bson:"roleRewards"`
+}
+type RoleReward struct {
+	RoleID string `bson:"roleID"`
+	Type   string `bson:"type"`
+	// Achievement that earns the role, for achievement rewards.
+	Achievement int `bson:"achievement"`
+	// Wins that earn the role, for win rewards.
+	Wins int64 `bson:"wins"`
+	// Difficulty the wins or leaderboard spot count on, wins count on every difficulty if empty.
+	Difficulty string `bson:"difficulty"`
+}
+type RoleRewardLogEntry struct {
+	GuildID string `bson:"guildID"`
+	UserID  string `bson:"userID"`
+	RoleID  string `bson:"roleID"`
+	Action  string `bson:"action"`
+	Reason  string `bson:"reason"`
+	// The reward the role belongs to, to describe it in the language of whoever reads the log.
+	Reward RoleReward `bson:"reward"`
+	At     time.Time  `bson:"at"`
 }
 type UserData struct {
 	UserID       string                    `bson:"userID"`
 	Difficulties map[string]DifficultyData `bson:"difficulties"`
-	Achievements []int                     `bson:"achievements"`
+	Achievements []AchievementUnlock       `bson:"achievements"`
+	Guilds       []string                  `bson:"guilds"`
+	Settings     UserSettings              `bson:"settings"`
+	// Progress towards locked achievements counting a stat, by achievement ID.
+	AchievementProgress map[string]int64 `bson:"achievementProgress"`
+	// Coins to spend in the shop and the IDs of the items bought with them.
+	Balance   int64    `bson:"balance"`
+	Inventory []string `bson:"inventory"`
+	// Day the daily challenge was last won, as YYYY-MM-DD in UTC.
+	LastDaily string `bson:"lastDaily"`
+	// Streak shields held, each one keeps a win streak alive through a game that wasn't won.
+	StreakShields int64 `bson:"streakShields"`
+	// Locale of the last game, used for notifications outside of interactions.
+	Locale string `bson:"locale"`
+	// Users whose new personal bests the user wants to hear about when they beat their own.
+	Subscriptions []string `bson:"subscriptions"`
+	// Users ranked against the user on their friend leaderboard.
+	Friends []string `bson:"friends"`
+}
+type AchievementUnlock struct {
+	ID         int       `bson:"id"`
+	UnlockedAt time.Time `bson:"unlockedAt"`
+	// Guild the achievement was unlocked in, empty for direct messages.
+	GuildID string `bson:"guildID"`
+	// Highest tier reached of a tiered achievement.
+	Tier string `bson:"tier,omitempty"`
+}
+type UserSettings struct {
+	DefaultDifficulty string `bson:"defaultDifficulty"`
+	Theme             string `bson:"theme"`
+	FlagModeOn        bool   `bson:"flagModeOn"`
+	NoPing            bool   `bson:"noPing"`
+	EphemeralResults  bool   `bson:"ephemeralResults"`
+	HideFromGlobal    bool   `bson:"hideFromGlobal"`
+	// Shop item IDs of the equipped profile badge and end-game message.
+	Badge      string `bson:"badge"`
+	EndMessage string `bson:"endMessage"`
+	// Whether streak shields are saved instead of used up by the next game that isn't won.
+	NoStreakShield bool `bson:"noStreakShield"`
+	// Whether to get a direct message when someone passes the user on a leaderboard.
+	BeatenDMs bool `bson:"beatenDMs"`
+	// Whether others are kept from spectating the games of the user.
+	NoSpectators bool `bson:"noSpectators"`
 }
 type Blacklist struct {
 	UserID  string `bson:"userID"`
@@ -60,6 +153,44 @@ type LeaderboardMessage struct {
 	ChannelID  string `bson:"channelID"`
 	MessageID  string `bson:"messageID"`
 	Difficulty int    `bson:"difficulty"`
+	Period     string `bson:"period"`
+}
+type Season struct {
+	Name     string    `bson:"name"`
+	Start    time.Time `bson:"start"`
+	End      time.Time `bson:"end"`
+	Archived bool      `bson:"archived"`
+}
+type SeasonStanding struct {
+	Season     string  `bson:"season"`
+	GuildID    string  `bson:"guildID"`
+	Difficulty int     `bson:"difficulty"`
+	Preset     string  `bson:"preset"`
+	Rank       int64   `bson:"rank"`
+	UserID     string  `bson:"userId"`
+	Time       float64 `bson:"time"`
+}
+type CustomPreset struct {
+	Name             string `bson:"name"`
+	Key              string `bson:"key"`
+	Bombs            int64  `bson:"bombs"`
+	SurroundingBombs bool   `bson:"surroundingBombs"`
+	NoStartSpot      bool   `bson:"noStartSpot"`
+	CreatedBy        string `bson:"createdBy"`
+}
+type GameRecord struct {
+	UserID     string    `bson:"userID" json:"userID"`
+	GuildID    string    `bson:"guildID" json:"guildID"`
+	Difficulty string    `bson:"difficulty" json:"difficulty"`
+	Preset     string    `bson:"preset" json:"preset,omitempty"`
+	Outcome    string    `bson:"outcome" json:"outcome"`
+	StartedAt  time.Time `bson:"startedAt" json:"startedAt"`
+	EndedAt    time.Time `bson:"endedAt" json:"endedAt"`
+	Duration   float64   `bson:"duration" json:"duration"`
+	Clicks     int64     `bson:"clicks" json:"clicks"`
+	FlagClicks int64     `bson:"flagClicks" json:"flagClicks"`
+	Chords     int64     `bson:"chords" json:"chords"`
+	Race       bool      `bson:"race" json:"race"`
 }
 type BotConfig struct {
 	BotID    string       `bson:"botID"`
@@ -72,6 +203,106 @@ var Collections = []string{
 	"blacklists",
 	"leaderboardmessages",
 	"botconfig",
+	"leaderboardentries",
+	"seasons",
+	"seasonarchives",
+	"custompresets",
+	"games",
+	"rolerewardlog",
+}
+
+// Map collection names to the indexes they should have.
+var Indexes = map[string][]mongo.IndexModel{
+	"leaderboardentries": {
+		{
+			Keys: bson.D{
+				{Key: "guildID", Value: 1},
+				{Key: "difficulty", Value: 1},
+				{Key: "preset", Value: 1},
+				{Key: "period", Value: 1},
+				{Key: "userId", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "guildID", Value: 1},
+				{Key: "difficulty", Value: 1},
+				{Key: "preset", Value: 1},
+				{Key: "period", Value: 1},
+				{Key: "time", Value: 1},
+				{Key: "userId", Value: 1},
+			},
+		},
+	},
+	"custompresets": {
+		{
+			Keys:    bson.D{{Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	},
+	"userdata": {
+		{
+			Keys: bson.D{{Key: "userID", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "guilds", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "settings.hideFromGlobal", Value: 1}},
+		},
+	},
+	"seasons": {
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "start", Value: 1}, {Key: "end", Value: 1}},
+		},
+	},
+	"seasonarchives": {
+		{
+			Keys: bson.D{
+				{Key: "season", Value: 1},
+				{Key: "guildID", Value: 1},
+				{Key: "difficulty", Value: 1},
+				{Key: "preset", Value: 1},
+				{Key: "rank", Value: 1},
+			},
+		},
+	},
+	"games": {
+		{
+			Keys: bson.D{{Key: "userID", Value: 1}, {Key: "endedAt", Value: -1}},
+		},
+		{
+			Keys: bson.D{
+				{Key: "userID", Value: 1},
+				{Key: "difficulty", Value: 1},
+				{Key: "endedAt", Value: -1},
+			},
+		},
+	},
+	"rolerewardlog": {
+		{
+			Keys: bson.D{{Key: "guildID", Value: 1}, {Key: "at", Value: -1}},
+		},
+	},
+}
+
+// Indexes that were replaced and have to be dropped before the new ones can be created.
+var ObsoleteIndexes = map[string][]string{
+	"leaderboardentries": {
+		"guildID_1_difficulty_1_userId_1",
+		"guildID_1_difficulty_1_time_1_userId_1",
+		"guildID_1_difficulty_1_period_1_userId_1",
+		"guildID_1_difficulty_1_period_1_time_1_userId_1",
+	},
 }
 
 func DbInit() *mongo.Client {
@@ -104,6 +335,25 @@ func CollectionCheck(d *mongo.Database) {
 	}
 }
 
+func IndexCheck(d *mongo.Database) {
+	for collectionName, indexNames := range ObsoleteIndexes {
+		for _, indexName := range indexNames {
+			if _, err := d.Collection(collectionName).Indexes().DropOne(context.TODO(), indexName); err == nil {
+				fmt.Printf("Dropped obsolete index %s on %s\n", indexName, collectionName)
+			}
+		}
+	}
+
+	for collectionName, indexes := range Indexes {
+		names, err := d.Collection(collectionName).Indexes().CreateMany(context.TODO(), indexes)
+		if err != nil {
+			fmt.Printf("Failed to create indexes for %s\n%v\n", collectionName, err)
+			continue
+		}
+		fmt.Printf("Verified indexes %v on %s\n", names, collectionName)
+	}
+}
+
 func blacklistUser(userID, message string) {
 	filter := bson.D{{
 		Key:   "userID",
@@ -180,6 +430,18 @@ func getUserData(userID string) UserData {
 	return userData
 }
 
+// Reads unlocks stored before they had a timestamp, which are only the ID of the achievement.
+func (u *AchievementUnlock) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+	if id, ok := raw.AsInt64OK(); ok {
+		*u = AchievementUnlock{ID: int(id)}
+		return nil
+	}
+
+	type unlock AchievementUnlock
+	return raw.Unmarshal((*unlock)(u))
+}
+
 func getBlacklist(userID string) Blacklist {
 	var blacklistInfo Blacklist
 	filter := bson.D{{
@@ -207,7 +469,7 @@ func getLeaderboardMessages() []LeaderboardMessage {
 	return results
 }
 
-func addLeaderboardMessage(guildID, channelID, messageID string, difficulty int) {
+func addLeaderboardMessage(guildID, channelID, messageID string, difficulty int, period string) {
 	filter := bson.D{{
 		Key:   "guildID",
 		Value: guildID,
@@ -224,6 +486,7 @@ func addLeaderboardMessage(guildID, channelID, messageID string, difficulty int)
 		ChannelID:  channelID,
 		MessageID:  messageID,
 		Difficulty: difficulty,
+		Period:     period,
 	}
 
 	data, err := bson.Marshal(newData)
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Items sold in the shop.
+//
+//go:embed shop.json
+var shopFile []byte
+
+// Kinds of shop items.
+const (
+	ItemTheme      = "theme"
+	ItemBadge      = "badge"
+	ItemEndMessage = "endMessage"
+)
+
+type ShopItem struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Name of the theme of themes, the emoji of badges or the text of end messages.
+	Value string `json:"value"`
+	Price int64  `json:"price"`
+}
+
+var ShopItems = make(map[string]ShopItem)
+
+// IDs of the shop items in the order they are listed in.
+var ShopOrder []string
+
+// Coins for a win on each difficulty.
+var difficultyCoins = map[string]int64{
+	"easy":   5,
+	"medium": 15,
+	"hard":   40,
+}
+
+const (
+	// Extra coins for every game of the win streak, up to the maximum.
+	streakCoins    int64 = 2
+	maxStreakCoins int64 = 20
+	// Coins for the first win of the day on the difficulty of the daily challenge.
+	dailyChallengeCoins int64 = 50
+)
+
+// Smallest amount of coins /admin coins takes.
+var minCoins float64 = 1
+
+var (
+	ErrNotEnoughCoins = &localizedError{id: "error.notEnoughCoins"}
+	ErrItemOwned      = &localizedError{id: "error.itemOwned"}
+	ErrUnknownItem    = &localizedError{id: "error.unknownItem"}
+)
+
+// Loads the shop items and fills the item choices of /shop, so it has to run after loadLocales.
+func loadShop() {
+	var items []ShopItem
+	if err := json.Unmarshal(shopFile, &items); err != nil {
+		panic(err)
+	}
+
+	for _, item := range items {
+		if item.Kind == ItemTheme {
+			if _, ok := Themes[item.Value]; !ok {
+				panic(fmt.Sprintf("shop item %s sells unknown theme %s", item.ID, item.Value))
+			}
+		}
+		ShopItems[item.ID] = item
+		ShopOrder = append(ShopOrder, item.ID)
+	}
+
+	setShopChoices()
+	fmt.Printf("Loaded %d shop items\n", len(ShopItems))
+}
+
+func setShopChoices() {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, id := range ShopOrder {
+		// Discord allows at most 25 choices.
+		if len(choices) >= 25 {
+			break
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:              ShopItems[id].Name,
+			NameLocalizations: localizations("shop.item." + id),
+			Value:             id,
+		})
+	}
+
+	for _, command := range Commands {
+		if command.Name != "shop" {
+			continue
+		}
+		for _, subcommand := range command.Options {
+			for _, option := range subcommand.Options {
+				if option.Name == "item" {
+					option.Choices = choices
+				}
+			}
+		}
+	}
+}
+
+func (item ShopItem) localizedName(locale string) string {
+	if message, ok := Messages.Lookup(locale, "shop.item."+item.ID); ok {
+		return message.Text
+	}
+
+	return item.Name
+}
+
+// Gets whether the user can play with the theme, themes sold in the shop have to be bought first.
+func canUseTheme(userData UserData, theme string) bool {
+	for _, item := range ShopItems {
+		if item.Kind == ItemTheme && item.Value == theme {
+			return isInArray(item.ID, userData.Inventory)
+		}
+	}
+
+	return true
+}
+
+// Gets the equipped item of a kind, if the user still owns it.
+func equippedItem(userData UserData, id, kind string) (ShopItem, bool) {
+	item, ok := ShopItems[id]
+	if !ok || item.Kind != kind || !isInArray(id, userData.Inventory) {
+		return ShopItem{}, false
+	}
+
+	return item, true
+}
+
+// Gets the coins a win on the difficulty with the given win streak is worth.
+func winCoins(difficulty string, streak int64) int64 {
+	bonus := streak * streakCoins
+	if bonus > maxStreakCoins {
+		bonus = maxStreakCoins
+	}
+
+	return difficultyCoins[difficulty] + bonus
+}
+
+// Gets the difficulty of the daily challenge of the day, which changes every day at midnight UTC.
+func dailyChallenge(t time.Time) string {
+	return difficultyOrder[t.UTC().Unix()/(24*60*60)%int64(len(difficultyOrder))]
+}
+
+// Adds coins to the balance of the user, a negative amount takes them away without going below
+// zero. Gets the new balance.
+func addCoins(userID string, amount int64) (int64, error) {
+	balance := bson.D{{Key: "$ifNull", Value: bson.A{"$balance", 0}}}
+	update := bson.A{bson.D{{Key: "$set", Value: bson.D{{
+		Key: "balance",
+		Value: bson.D{{Key: "$max", Value: bson.A{
+			0,
+			bson.D{{Key: "$add", Value: bson.A{balance, amount}}},
+		}}},
+	}}}}}
+
+	var result struct {
+		Balance int64 `bson:"balance"`
+	}
+	err := d.Collection("userdata").FindOneAndUpdate(
+		context.TODO(),
+		bson.D{{Key: "userID", Value: userID}},
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&result)
+
+	return result.Balance, err
+}
+
+// Gives the coins of the daily challenge, if the user hasn't claimed them today. Both happen in
+// one update, so games ending at the same time can't claim them twice.
+func claimDailyChallenge(userID string, now time.Time) (bool, error) {
+	today := now.UTC().Format("2006-01-02")
+	result, err := d.Collection("userdata").UpdateOne(
+		context.TODO(),
+		bson.D{
+			{Key: "userID", Value: userID},
+			{Key: "lastDaily", Value: bson.D{{Key: "$ne", Value: today}}},
+		},
+		bson.D{
+			{Key: "$set", Value: bson.D{{Key: "lastDaily", Value: today}}},
+			{Key: "$inc", Value: bson.D{{Key: "balance", Value: dailyChallengeCoins}}},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// Pays for an item out of the balance of the user. The balance check and the payment are one
+// update, so two purchases at once can't spend the same coins.
+func buyItem(userID string, item ShopItem) error {
+	result, err := d.Collection("userdata").UpdateOne(
+		context.TODO(),
+		bson.D{
+			{Key: "userID", Value: userID},
+			{Key: "balance", Value: bson.D{{Key: "$gte", Value: item.Price}}},
+			{Key: "inventory", Value: bson.D{{Key: "$ne", Value: item.ID}}},
+		},
+		bson.D{
+			{Key: "$inc", Value: bson.D{{Key: "balance", Value: -item.Price}}},
+			{Key: "$addToSet", Value: bson.D{{Key: "inventory", Value: item.ID}}},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount > 0 {
+		return nil
+	}
+
+	if isInArray(item.ID, getUserData(userID).Inventory) {
+		return ErrItemOwned
+	}
+	return ErrNotEnoughCoins
+}
+
+// Pays out the coins of a win and the daily challenge, getting the lines telling the player.
+func payWin(game *MinesweeperGame, coins int64, endedAt time.Time) string {
+	var lines []string
+	if coins > 0 {
+		if _, err := addCoins(game.UserID, coins); err != nil {
+			fmt.Println(err)
+		} else {
+			lines = append(lines, tr(game.Locale, "game.coins", coins))
+		}
+	}
+
+	if game.Difficulty == dailyChallenge(endedAt) {
+		claimed, err := claimDailyChallenge(game.UserID, endedAt)
+		if err != nil {
+			fmt.Println(err)
+		}
+		if claimed {
+			lines = append(lines, tr(game.Locale, "game.dailyChallenge", dailyChallengeCoins, strings.ToUpper(game.Difficulty)))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func generateShopEmbed(locale string, userData UserData) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       tr(locale, "shop.title"),
+		Description: tr(locale, "shop.balance", userData.Balance, strings.ToUpper(dailyChallenge(time.Now())), dailyChallengeCoins),
+		Color:       randomEmbedColor(),
+	}
+
+	for _, id := range ShopOrder {
+		item := ShopItems[id]
+		value := tr(locale, "shop.price", item.Price)
+		if isInArray(id, userData.Inventory) {
+			value = tr(locale, "shop.owned")
+		}
+		preview := item.Value
+		if item.Kind == ItemTheme {
+			preview = getTheme(item.Value).Description
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("%s (%s)", item.localizedName(locale), tr(locale, "shop.kind."+item.Kind)),
+			Value: fmt.Sprintf("%s\n%s", preview, value),
+		})
+	}
+
+	return embed
+}
+
+func ShopCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+	userID, _ := getUserID(i)
+	locale := interactionLocale(i)
+
+	subcommand := i.ApplicationCommandData().Options[0]
+	optionMap := mapOptions(subcommand.Options)
+
+	var content string
+	switch subcommand.Name {
+	case "buy":
+		item, ok := ShopItems[optionMap["item"].StringValue()]
+		if !ok {
+			cmdError(s, i, ErrUnknownItem)
+			return
+		}
+
+		switch err := buyItem(userID, item); err {
+		case nil:
+			content = tr(locale, "shop.bought", item.localizedName(locale), item.Price)
+		case ErrItemOwned:
+			content = tr(locale, "shop.alreadyOwned", item.localizedName(locale))
+		case ErrNotEnoughCoins:
+			content = tr(locale, "shop.notEnough", item.localizedName(locale), item.Price)
+		default:
+			cmdError(s, i, err)
+			return
+		}
+	case "equip":
+		item, ok := ShopItems[optionMap["item"].StringValue()]
+		if !ok {
+			cmdError(s, i, ErrUnknownItem)
+			return
+		}
+		if !isInArray(item.ID, getUserData(userID).Inventory) {
+			content = tr(locale, "shop.notOwned", item.localizedName(locale))
+			break
+		}
+
+		key, value := "badge", item.ID
+		switch item.Kind {
+		case ItemTheme:
+			key, value = "theme", item.Value
+		case ItemEndMessage:
+			key = "endMessage"
+		}
+		if err := setUserSetting(userID, key, value); err != nil {
+			cmdError(s, i, err)
+			return
+		}
+		content = tr(locale, "shop.equipped", item.localizedName(locale))
+	case "unequip":
+		kind := optionMap["kind"].StringValue()
+		if err := setUserSetting(userID, kind, ""); err != nil {
+			cmdError(s, i, err)
+			return
+		}
+		content = tr(locale, "shop.unequipped", tr(locale, "shop.kind."+kind))
+	}
+
+	data := &discordgo.InteractionResponseData{
+		Flags:   1 << 6,
+		Content: content,
+		Embeds:  []*discordgo.MessageEmbed{generateShopEmbed(locale, getUserData(userID))},
+	}
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	}); err != nil {
+		cmdError(s, i, err)
+	}
+}
@@ -181,6 +181,11 @@ func HandleComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 
+	if LeaderboardPageRegex.MatchString(customID) {
+		go HandleLeaderboardPage(s, i)
+		return
+	}
+
 	if handler, ok := ComponentHandlers[customID]; ok {
 		go handler(s, i)
 		return
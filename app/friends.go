@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Most friends a user can have.
+const maxFriends = 50
+
+// Leaderboards of a friend group use this prefix and the ID of their owner in place of a guild ID.
+const friendsScopePrefix = "friends-"
+
+var ErrFriendsPeriod = &localizedError{id: "error.friendsPeriod"}
+
+func friendsScope(userID string) string {
+	return friendsScopePrefix + userID
+}
+
+// Gets the owner of a friend leaderboard, if the guild ID of the leaderboard is one.
+func friendsScopeOwner(guildID string) (string, bool) {
+	if !strings.HasPrefix(guildID, friendsScopePrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(guildID, friendsScopePrefix), true
+}
+
+// Gets the users ranked on the friend leaderboard of the user, which are their friends and themselves.
+func friendGroup(userID string) []string {
+	return append([]string{userID}, getUserData(userID).Friends...)
+}
+
+func addFriend(userID, friendID string) error {
+	_, err := d.Collection("userdata").UpdateOne(
+		context.TODO(),
+		bson.D{{Key: "userID", Value: userID}},
+		bson.D{{Key: "$addToSet", Value: bson.D{{Key: "friends", Value: friendID}}}},
+		options.Update().SetUpsert(true),
+	)
+
+	return err
+}
+
+func removeFriend(userID, friendID string) error {
+	_, err := d.Collection("userdata").UpdateOne(
+		context.TODO(),
+		bson.D{{Key: "userID", Value: userID}},
+		bson.D{{Key: "$pull", Value: bson.D{{Key: "friends", Value: friendID}}}},
+	)
+
+	return err
+}
+
+// Lists the friends of the user with their personal best on every difficulty.
+func generateFriendsEmbed(locale string, userData UserData) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       tr(locale, "friends.title"),
+		Description: tr(locale, "friends.none"),
+		Color:       randomEmbedColor(),
+	}
+	if len(userData.Friends) == 0 {
+		return embed
+	}
+
+	cursor, err := d.Collection("userdata").Find(
+		context.TODO(),
+		bson.D{{Key: "userID", Value: bson.D{{Key: "$in", Value: userData.Friends}}}},
+	)
+	friends := make(map[string]UserData, len(userData.Friends))
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		var results []UserData
+		if err := cursor.All(context.TODO(), &results); err != nil {
+			fmt.Println(err)
+		}
+		for _, friend := range results {
+			friends[friend.UserID] = friend
+		}
+	}
+
+	lines := make([]string, 0, len(userData.Friends))
+	for _, friendID := range userData.Friends {
+		bests := make([]string, 0, len(difficultyOrder))
+		for _, difficulty := range difficultyOrder {
+			best := tr(locale, "profile.neverPlayed")
+			if pb := friends[friendID].Difficulties[difficulty].PB; pb > 0 {
+				best = humanizeDuration(locale, secondsDuration(pb), 2)
+			}
+			bests = append(bests, fmt.Sprintf("%s %s", strings.ToUpper(difficulty), best))
+		}
+		lines = append(lines, fmt.Sprintf("<@%s>: %s", friendID, strings.Join(bests, " · ")))
+	}
+	embed.Description = strings.Join(lines, "\n")
+
+	return embed
+}
+
+func FriendsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+	userID, _ := getUserID(i)
+	locale := interactionLocale(i)
+
+	subcommand := i.ApplicationCommandData().Options[0]
+	optionMap := mapOptions(subcommand.Options)
+
+	var content string
+	switch subcommand.Name {
+	case "add":
+		target := optionMap["user"].UserValue(s)
+		friends := getUserData(userID).Friends
+		switch {
+		case target.ID == userID || target.Bot:
+			content = tr(locale, "friends.invalid")
+		case isInArray(target.ID, friends):
+			content = tr(locale, "friends.already", target.ID)
+		case len(friends) >= maxFriends:
+			content = tr(locale, "friends.full", maxFriends)
+		default:
+			if err := addFriend(userID, target.ID); err != nil {
+				cmdError(s, i, err)
+				return
+			}
+			content = tr(locale, "friends.added", target.ID)
+		}
+	case "remove":
+		target := optionMap["user"].UserValue(s)
+		if err := removeFriend(userID, target.ID); err != nil {
+			cmdError(s, i, err)
+			return
+		}
+		content = tr(locale, "friends.removed", target.ID)
+	}
+
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:           1 << 6,
+			Content:         content,
+			Embeds:          []*discordgo.MessageEmbed{generateFriendsEmbed(locale, getUserData(userID))},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	}); err != nil {
+		cmdError(s, i, err)
+	}
+}
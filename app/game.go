@@ -1,11 +1,13 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"main/humanizetime"
+	"io"
+	"main/boardimage"
+	"main/boardtext"
 	"main/minesweeper"
-	"strconv"
 	"strings"
 	"time"
 
@@ -26,50 +28,48 @@ var (
 
 // StartGame starts a new Minesweeper game for the user.
 func StartGame(s *discordgo.Session, i *discordgo.InteractionCreate, game *minesweeper.Game, difficulty, userID string) {
+	userData := getUserData(userID)
 	newGame := MinesweeperGame{
-		UserID:       userID,
-		GuildID:      i.GuildID,
-		ChannelID:    i.ChannelID,
-		Game:         game,
-		Difficulty:   difficulty,
-		Achievements: make(map[int]Achievement),
+		UserID:      userID,
+		GuildID:     i.GuildID,
+		ChannelID:   i.ChannelID,
+		Game:        game,
+		Difficulty:  difficulty,
+		Theme:       gameTheme(i, userData),
+		Locale:      interactionLocale(i),
+		Removed:     make(chan struct{}),
+		Interaction: i.Interaction,
+	}
+	if userData.Settings.FlagModeOn {
+		newGame.Flags |= FlagEnabled
 	}
 
-	content := "Click the <:clickme:1119511692825604096> to start the game!"
+	content := tr(newGame.Locale, "game.clickStart", getTheme(newGame.Theme).StartHere.mention())
 	if !game.HasStartPosition {
-		content = "Click anywhere to start the game!"
+		content = tr(newGame.Locale, "game.clickAnywhere")
 	}
 
-	// Send the initial message with the game board.
+	// Send the initial message with the game board, inside a thread of its own if the guild wants one.
 	board := GenerateBoard(&newGame, game.HasStartPosition, false)
-	msg, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Content:    &content,
-		Components: &board,
-	})
+	msg, err := startGameThread(s, i, &newGame, content, board)
 	if err != nil {
-		cmdError(s, i, err)
-		return
+		fmt.Println(err)
+	}
+	if msg == nil {
+		msg, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content:    &content,
+			Components: &board,
+		})
+		if err != nil {
+			cmdError(s, i, err)
+			return
+		}
 	}
-
-	// Add flag and end game buttons to the message.
-	flagRow := discordgo.ActionsRow{}
-	flagRow.Components = append(flagRow.Components, &discordgo.Button{
-		CustomID: "minesweeperflagbutton",
-		Style:    discordgo.DangerButton,
-		Label:    "OFF",
-		Emoji: discordgo.ComponentEmoji{
-			Name: "🚩",
-		},
-	}, &discordgo.Button{
-		CustomID: "endgamebutton",
-		Style:    discordgo.DangerButton,
-		Label:    "End game",
-	})
 
 	// Send the flag and end game buttons as a separate message.
 	flagMsg, err := s.ChannelMessageSendComplex(msg.ChannelID, &discordgo.MessageSend{
 		Reference:  msg.Reference(),
-		Components: []discordgo.MessageComponent{flagRow},
+		Components: []discordgo.MessageComponent{generateFlagRow(&newGame)},
 	}, RequestOption)
 	if err != nil {
 		cmdError(s, i, err)
@@ -80,20 +80,23 @@ func StartGame(s *discordgo.Session, i *discordgo.InteractionCreate, game *mines
 	newGame.BoardID = msg.ID
 	newGame.FlagID = flagMsg.ID
 
+	// The achievement tracker runs until the game ends, so it only starts once nothing can fail anymore.
+	if difficulty != "custom" {
+		newGame.Achievements = newAchievementTracker()
+	}
+
 	// Configure automatic end game timer.
-	timer := time.NewTimer(time.Duration(EndAfter) * time.Second)
+	endAfter := gameEndAfter(i.GuildID)
+	timer := time.NewTimer(time.Duration(endAfter) * time.Second)
 	channel := make(chan struct{})
 	newGame.EndGameChan = &channel
 
 	// Start automatic end game timer.
-	if EndAfter != 0 {
+	if endAfter != 0 {
 		go func() {
 			for {
 				select {
 				case <-timer.C:
-					for id, achievement := range AwardAchievements(&newGame, minesweeper.TimedEnd, nil, false, false, true) {
-						newGame.Achievements[id] = achievement
-					}
 					HandleGameEnd(s, &newGame, minesweeper.TimedEnd, false)
 					return
 				case <-channel:
@@ -105,23 +108,131 @@ func StartGame(s *discordgo.Session, i *discordgo.InteractionCreate, game *mines
 	}
 
 	// Store the new game object in the Games map.
-	Games[userID] = &newGame
+	addGame(&newGame)
+}
+
+// Gets the open game of the user.
+func getGame(userID string) (*MinesweeperGame, bool) {
+	gamesMutex.RLock()
+	defer gamesMutex.RUnlock()
+
+	game, ok := Games[userID]
+	return game, ok
+}
+
+// Gets the open game whose flag and end game buttons are on the message.
+func gameByFlagID(messageID string) (*MinesweeperGame, bool) {
+	gamesMutex.RLock()
+	defer gamesMutex.RUnlock()
+
+	for _, game := range Games {
+		if game.FlagID == messageID {
+			return game, true
+		}
+	}
+
+	return nil, false
+}
+
+func addGame(game *MinesweeperGame) {
+	gamesMutex.Lock()
+	defer gamesMutex.Unlock()
+
+	Games[game.UserID] = game
+}
+
+func removeGame(userID string) {
+	gamesMutex.Lock()
+	defer gamesMutex.Unlock()
+
+	delete(Games, userID)
+}
+
+// Opens a thread for the game below the interaction response and sends the board into it,
+// returning a nil message if the guild doesn't use threads or the channel can't have them.
+func startGameThread(s *discordgo.Session, i *discordgo.InteractionCreate, game *MinesweeperGame, content string, board []discordgo.MessageComponent) (*discordgo.Message, error) {
+	if i.GuildID == "" || !getGuildConfig(i.GuildID).GameThreads {
+		return nil, nil
+	}
+	if channel, err := getChannel(s, i.ChannelID); err != nil || channel.Type != discordgo.ChannelTypeGuildText {
+		return nil, err
+	}
+
+	notice := tr(game.Locale, "game.summary", game.UserID, strings.ToUpper(game.Difficulty))
+	response, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &notice})
+	if err != nil {
+		return nil, err
+	}
+
+	thread, err := s.MessageThreadStartComplex(response.ChannelID, response.ID, &discordgo.ThreadStart{
+		Name:                tr(game.Locale, "game.threadName", strings.ToUpper(game.Difficulty)),
+		AutoArchiveDuration: 60,
+	}, RequestOption)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := s.ChannelMessageSendComplex(thread.ID, &discordgo.MessageSend{
+		Content:    content,
+		Components: board,
+	}, RequestOption)
+	if err != nil {
+		return nil, err
+	}
+
+	game.ChannelID = thread.ID
+	game.ThreadID = thread.ID
+	return msg, nil
+}
+
+// Archives the thread of the game, if it was played in one.
+func archiveGameThread(s *discordgo.Session, game *MinesweeperGame) {
+	if game.ThreadID == "" {
+		return
+	}
+
+	archived := true
+	if _, err := s.ChannelEditComplex(game.ThreadID, &discordgo.ChannelEdit{Archived: &archived}, RequestOption); err != nil {
+		fmt.Println(err)
+	}
 }
 
 // HandleGameEnd handles the end of the game and sends the appropriate message.
 func HandleGameEnd(s *discordgo.Session, game *MinesweeperGame, event int, addToBoard bool) {
+	game.mutex.Lock()
+	defer game.mutex.Unlock()
+
+	endGame(s, game, event, addToBoard)
+}
+
+// endGame is HandleGameEnd for callers that already hold the lock of the game.
+func endGame(s *discordgo.Session, game *MinesweeperGame, event int, addToBoard bool) {
 	defer func() {
 		if err := recover(); err != nil {
 			handlePanic(err)
 		}
 	}()
+	// A click, the end game button, the timeout and admins can all end the game, only the first one does.
+	select {
+	case <-game.Removed:
+		return
+	default:
+	}
+	// Remove the game from the active games map however ending it goes, spectators wait for that.
+	defer func() {
+		removeGame(game.UserID)
+		close(game.Removed)
+	}()
 	close(*game.EndGameChan)
+	// Wins forced by an admin are not played, so they don't unlock achievements about playing.
+	forcedWin := event == minesweeper.Won && !addToBoard
 
 	// Calculate the time taken in the game and format it as a human-readable string.
-	gameDuration := time.Since(game.StartTime)
-	timeString := fmt.Sprintf("\nYour time was %s", humanizetime.HumanizeDuration(gameDuration, 3))
+	endedAt := time.Now()
+	gameDuration := endedAt.Sub(game.StartTime)
+	timeString := "\n" + tr(game.Locale, "game.time", humanizeDuration(game.Locale, gameDuration, 3))
 	if game.StartTime.IsZero() {
-		timeString = "\nyou never even started the game.."
+		timeString = "\n" + tr(game.Locale, "game.neverStarted")
 	}
 
 	// Determine the content string based on the event that caused the game to end.
@@ -131,34 +242,34 @@ func HandleGameEnd(s *discordgo.Session, game *MinesweeperGame, event int, addTo
 		userData.Difficulties = make(map[string]DifficultyData)
 	}
 	if userData.Achievements == nil {
-		userData.Achievements = make([]int, 0)
+		userData.Achievements = make([]AchievementUnlock, 0)
 	}
 
+	sarcastic := !getGuildConfig(game.GuildID).NoSarcasm
 	boardContent := ""
+	// Coins earned by the game, only real wins on the presets pay out.
+	var coins int64
+	// Personal best before and after the game, and the leaderboard placements of a win.
+	var oldPB, newPB float64
+	var placements []Placement
 	switch event {
 	case minesweeper.ManualEnd:
-		content += getRandomMessage(SarcasticGiveUpMessages)
-		boardContent = "LOL, giving up already?"
+		content += endMessage(game.Locale, sarcastic, event, "sarcastic.giveUp")
+		boardContent = boardMessage(game.Locale, sarcastic, event)
 
 		dd := userData.Difficulties[game.Difficulty]
-		if dd.WinStreak > 0 {
-			boardContent += fmt.Sprintf("\nLost a winstreak of **%d**\n", dd.WinStreak)
-		}
-		dd.WinStreak = 0
+		boardContent += breakStreak(game.Locale, &userData, &dd)
 		userData.Difficulties[game.Difficulty] = dd
 	case minesweeper.TimedEnd:
-		content += getRandomMessage(SarcasticTimeOverMessages)
-		boardContent = "Jesus christ, it does NOT take that long!"
+		content += endMessage(game.Locale, sarcastic, event, "sarcastic.timeOver")
+		boardContent = boardMessage(game.Locale, sarcastic, event)
 
 		dd := userData.Difficulties[game.Difficulty]
-		if dd.WinStreak > 0 {
-			boardContent += fmt.Sprintf("\nLost a winstreak of **%d**\n", dd.WinStreak)
-		}
-		dd.WinStreak = 0
+		boardContent += breakStreak(game.Locale, &userData, &dd)
 		userData.Difficulties[game.Difficulty] = dd
 	case minesweeper.Lost:
-		boardContent = "Game over. LOL."
-		content += getRandomMessage(SarcasticLostMessages)
+		boardContent = boardMessage(game.Locale, sarcastic, event)
+		content += endMessage(game.Locale, sarcastic, event, "sarcastic.lost")
 
 		if game.Difficulty == "custom" {
 			break
@@ -166,48 +277,62 @@ func HandleGameEnd(s *discordgo.Session, game *MinesweeperGame, event int, addTo
 
 		dd := userData.Difficulties[game.Difficulty]
 		dd.Losses++
-		if dd.WinStreak > 0 {
-			boardContent += fmt.Sprintf("\nLost a winstreak of **%d**\n", dd.WinStreak)
-		}
-		dd.WinStreak = 0
+		boardContent += breakStreak(game.Locale, &userData, &dd)
+		boardContent += rateSoloGame(game, dd, 0)
 		userData.Difficulties[game.Difficulty] = dd
 	case minesweeper.Won:
-		boardContent = "Wow, you managed to win!"
+		boardContent = boardMessage(game.Locale, sarcastic, event)
 		game.Flags |= Won
 
-		messages := getMessages(int64(gameDuration.Seconds()))
+		messages := wonMessageID(int64(gameDuration.Seconds()))
 		if gameDuration.Seconds() <= 0.2 {
-			messages = SarcasticOneClickMessages
+			messages = "sarcastic.oneClick"
 			addToBoard = false
 		}
 
-		content += getRandomMessage(messages)
-		if !addToBoard {
-			break
+		if item, ok := equippedItem(userData, userData.Settings.EndMessage, ItemEndMessage); ok {
+			content += item.Value
+		} else {
+			content += endMessage(game.Locale, sarcastic, event, messages)
 		}
-		if game.Difficulty == "custom" {
+		if !addToBoard {
 			break
 		}
 		entry := LeaderboardEntry{
-			Time:   gameDuration.Seconds(),
-			UserID: game.UserID,
-			Spot:   11,
+			Time:       gameDuration.Seconds(),
+			UserID:     game.UserID,
+			AchievedAt: time.Now(),
+		}
+		// Custom games only count towards the leaderboard of their preset.
+		if game.Difficulty == "custom" && game.Preset == "" {
+			break
 		}
 		if game.GuildID != "" {
-			addToLeaderboard(game.GuildID, game.Game.Difficulty, entry)
+			placements = append(placements, placeOnLeaderboard(game.GuildID, game.Game.Difficulty, game.Preset, entry))
+		}
+		placements = append(placements, placeOnLeaderboard("global", game.Game.Difficulty, game.Preset, entry))
+		if game.Difficulty == "custom" {
+			break
 		}
-		addToLeaderboard("global", game.Game.Difficulty, entry)
 
 		dd := userData.Difficulties[game.Difficulty]
 		dd.Wins++
 		dd.WinStreak++
-		boardContent += fmt.Sprintf("\nNew winstreak: **%d**", dd.WinStreak)
+		if dd.WinStreak > dd.BestStreak {
+			dd.BestStreak = dd.WinStreak
+		}
+		boardContent += "\n" + tr(game.Locale, "game.newStreak", dd.WinStreak)
+		boardContent += earnStreakShield(game.Locale, &userData, dd)
+		coins = winCoins(game.Difficulty, dd.WinStreak)
+		oldPB = dd.PB
 		if dd.PB > gameDuration.Seconds() || dd.PB == 0 {
 			dd.PB = gameDuration.Seconds()
 		}
+		newPB = dd.PB
 		if dd.PW < gameDuration.Seconds() {
 			dd.PW = gameDuration.Seconds()
 		}
+		boardContent += rateSoloGame(game, dd, 1)
 
 		if userData.Difficulties == nil {
 			userData.Difficulties = map[string]DifficultyData{}
@@ -215,23 +340,37 @@ func HandleGameEnd(s *discordgo.Session, game *MinesweeperGame, event int, addTo
 		userData.Difficulties[game.Difficulty] = dd
 	}
 
+	userData.Locale = game.Locale
+	if game.GuildID != "" && !isInArray(game.GuildID, userData.Guilds) {
+		userData.Guilds = append(userData.Guilds, game.GuildID)
+	}
+
+	achievementEvent := event
+	if forcedWin {
+		achievementEvent = minesweeper.Nothing
+	}
+	unlocked := finishAchievements(game, achievementEvent, gameDuration, &userData)
+
 	var embeds []*discordgo.MessageEmbed
 
-	newAchievements := len(game.Achievements)
+	newAchievements := len(unlocked)
 	// Check for unlocked achievements and update the user data.
 	if newAchievements > 0 {
 		newEmbed := &discordgo.MessageEmbed{}
 		newEmbed.Color = randomEmbedColor()
-		newEmbed.Title = "Achievements Unlocked"
+		newEmbed.Title = tr(game.Locale, "game.achievementsUnlocked")
 		newEmbed.Timestamp = time.Now().Format(time.RFC3339)
 
-		for ID, achievement := range game.Achievements {
-			if isInIntArray(ID, userData.Achievements) {
+		for ID, achievement := range unlocked {
+			if !userData.unlockAchievement(achievement, game, endedAt) {
 				newAchievements--
 				continue
 			}
-			userData.Achievements = append(userData.Achievements, ID)
-			newEmbed.Description += fmt.Sprintf("**%s:** %s\n", achievement.Name, achievement.Description)
+			name, description := achievement.localized(game.Locale, ID)
+			if badge := tierBadge(achievement.tier(game.Difficulty)); badge != "" {
+				name = badge + " " + name
+			}
+			newEmbed.Description += fmt.Sprintf("**%s:** %s\n", name, description)
 		}
 		embeds = append(embeds, newEmbed)
 	}
@@ -240,6 +379,14 @@ func HandleGameEnd(s *discordgo.Session, game *MinesweeperGame, event int, addTo
 		embeds = make([]*discordgo.MessageEmbed, 0)
 	}
 
+	if records := describeRecords(game.Locale, oldPB, newPB, placements); records != "" {
+		embeds = append(embeds, &discordgo.MessageEmbed{
+			Color:       randomEmbedColor(),
+			Title:       tr(game.Locale, "game.recordsTitle"),
+			Description: records,
+		})
+	}
+
 	// Update userdata record in the database.
 	filter := bson.D{{Key: "userID", Value: game.UserID}}
 	data, err := bson.Marshal(userData)
@@ -252,6 +399,32 @@ func HandleGameEnd(s *discordgo.Session, game *MinesweeperGame, event int, addTo
 	if err := bson.Unmarshal(data, &update); err != nil {
 		return
 	}
+	// Settings are only changed through /settings, writing them back could undo a change made during the game.
+	delete(update, "settings")
+	// Coins and items are only changed through atomic updates of their own, see economy.go.
+	delete(update, "balance")
+	delete(update, "inventory")
+	delete(update, "lastDaily")
+	// Subscriptions and friends are only changed through /subscribe and /friends.
+	delete(update, "subscriptions")
+	delete(update, "friends")
+	// Ratings are only changed through setRating, the race of an opponent could have just changed
+	// them. The other fields of the difficulties are set one by one to leave the ratings out.
+	if difficulties, ok := update["difficulties"].(bson.M); ok {
+		delete(update, "difficulties")
+		for difficulty, value := range difficulties {
+			fields, ok := value.(bson.M)
+			if !ok {
+				continue
+			}
+			for field, value := range fields {
+				if field == "rating" || field == "ratedGames" {
+					continue
+				}
+				update[fmt.Sprintf("difficulties.%s.%s", difficulty, field)] = value
+			}
+		}
+	}
 
 	request := d.Collection("userdata").FindOneAndUpdate(
 		context.TODO(),
@@ -264,19 +437,67 @@ func HandleGameEnd(s *discordgo.Session, game *MinesweeperGame, event int, addTo
 		fmt.Println(err)
 	}
 
-	boardContent += fmt.Sprintf("\n<@!%s>'s **%s** minesweeper game", game.UserID, strings.ToUpper(game.Difficulty))
+	if coins > 0 {
+		if lines := payWin(game, coins, endedAt); lines != "" {
+			boardContent += "\n" + lines
+		}
+	}
+
+	// Role rewards get a copy of the saved user data, as the decode above overwrote it.
+	var rewardData UserData
+	if err := bson.Unmarshal(data, &rewardData); err == nil {
+		go syncMemberRewards(s, game.GuildID, rewardData)
+	}
+	if len(placements) > 0 || newPB > 0 {
+		go notifyRecords(s, game, oldPB, newPB, placements)
+	}
+	if event == minesweeper.Won && addToBoard && game.GuildID != "" {
+		go syncLeaderboardRewards(s, game.GuildID, getGuildConfig(game.GuildID).RoleRewards)
+	}
+
+	// Wins forced by an admin are not real games and stay out of the history.
+	if event != minesweeper.Won || addToBoard {
+		recordGame(game, event, endedAt)
+	}
+
+	if game.Race != nil {
+		game.Race.finish(game.UserID, event)
+	}
+
+	boardContent += "\n" + tr(game.Locale, "game.summary", game.UserID, strings.ToUpper(game.Difficulty))
+
+	// Write out the final board, so it stays readable once the buttons are gone.
+	finalBoard := boardtext.Emoji(game.Game, boardtext.Options{
+		Reveal: true,
+		Won:    game.Flags&Won != 0,
+	})
+
+	// Attach the final board as an image, so it can be shared outside of Discord.
+	var files []*discordgo.File
+	if file, err := renderBoardFile(game); err != nil {
+		fmt.Println(err)
+	} else {
+		files = append(files, file)
+	}
 
 	// Send a message to the channel with the game result and time information.
-	if _, err := s.ChannelMessageSendComplex(game.ChannelID, &discordgo.MessageSend{
-		Content: fmt.Sprintf("%s%s", content, timeString),
+	result := &discordgo.MessageSend{
+		Content: fmt.Sprintf("%s%s\n%s", content, timeString, finalBoard),
 		Embeds:  embeds,
+		Files:   files,
 		Reference: &discordgo.MessageReference{
 			MessageID: game.BoardID,
 			ChannelID: game.ChannelID,
 			GuildID:   game.GuildID,
 		},
-	}); err != nil {
-		fmt.Println(err)
+	}
+	if userData.Settings.NoPing {
+		result.AllowedMentions = &discordgo.MessageAllowedMentions{}
+	}
+	if !userData.Settings.EphemeralResults || !sendEphemeralResult(s, game, result) {
+		if _, err := s.ChannelMessageSendComplex(game.ChannelID, result); err != nil {
+			fmt.Println(err)
+		}
 	}
 
 	// Update the game board message with the final state of the board.
@@ -289,9 +510,102 @@ func HandleGameEnd(s *discordgo.Session, game *MinesweeperGame, event int, addTo
 	if err != nil {
 		fmt.Println(err)
 	}
+	archiveGameThread(s, game)
+}
+
+// Sends the result of a game only to its player, as a followup of the last interaction with the game.
+// Returns false if the interaction has expired and the result has to be sent publicly instead.
+func sendEphemeralResult(s *discordgo.Session, game *MinesweeperGame, result *discordgo.MessageSend) bool {
+	if game.Interaction == nil {
+		return false
+	}
 
-	// Remove the game from the active games map.
-	delete(Games, game.UserID)
+	// The interaction may already be acknowledged, in which case this fails and the followup still works.
+	s.InteractionRespond(game.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+
+	if _, err := s.FollowupMessageCreate(game.Interaction, true, &discordgo.WebhookParams{
+		Content:         result.Content,
+		Embeds:          result.Embeds,
+		Files:           result.Files,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+		Flags:           1 << 6,
+	}); err != nil {
+		fmt.Println(err)
+		// Rewind the files so they can be sent again publicly.
+		for _, file := range result.Files {
+			if seeker, ok := file.Reader.(io.Seeker); ok {
+				seeker.Seek(0, io.SeekStart)
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// Generates the flag, end game and spectate buttons, the flag button showing whether flag mode is on.
+func generateFlagRow(game *MinesweeperGame) discordgo.ActionsRow {
+	flagButton := &discordgo.Button{
+		CustomID: "minesweeperflagbutton",
+		Style:    discordgo.DangerButton,
+		Label:    tr(game.Locale, "game.flagOff"),
+		Emoji: discordgo.ComponentEmoji{
+			Name: "🚩",
+		},
+	}
+	if game.Flags&FlagEnabled != 0 {
+		flagButton.Label = tr(game.Locale, "game.flagOn")
+		flagButton.Style = discordgo.SuccessButton
+	}
+
+	return discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{flagButton, &discordgo.Button{
+			CustomID: "endgamebutton",
+			Style:    discordgo.DangerButton,
+			Label:    tr(game.Locale, "game.endButton"),
+		}, &discordgo.Button{
+			CustomID: "spectatebutton",
+			Style:    discordgo.SecondaryButton,
+			Label:    tr(game.Locale, "game.spectateButton"),
+			Emoji: discordgo.ComponentEmoji{
+				Name: "👁️",
+			},
+		}},
+	}
+}
+
+// Renders the revealed board of a game as a PNG file.
+func renderBoardFile(game *MinesweeperGame) (*discordgo.File, error) {
+	var buffer bytes.Buffer
+	if err := boardimage.Encode(&buffer, game.Game, boardimage.Options{
+		Theme:  getTheme(game.Theme).imageTheme(),
+		Reveal: true,
+		Won:    game.Flags&Won != 0,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &discordgo.File{
+		Name:        "board.png",
+		ContentType: "image/png",
+		Reader:      bytes.NewReader(buffer.Bytes()),
+	}, nil
+}
+
+// rateSoloGame updates the rating of the player against the rating of the board when solo games are rated.
+func rateSoloGame(game *MinesweeperGame, dd DifficultyData, score float64) string {
+	boardRating, ok := BoardRatings[game.Difficulty]
+	if !RateSoloGames || !ok || game.Race != nil {
+		return ""
+	}
+
+	oldRating := ratingOf(dd)
+	rating := newRating(oldRating, boardRating, score, dd.RatedGames)
+	setRating(game.UserID, game.Difficulty, rating)
+
+	return "\n" + tr(game.Locale, "game.rating", oldRating, rating)
 }
 
 // GenerateBoard generates the message components for the game board.
@@ -303,6 +617,7 @@ func GenerateBoard(game *MinesweeperGame, firstGen, useSpotTypes bool) []discord
 	}()
 	var rows []discordgo.MessageComponent
 	var currentRow discordgo.ActionsRow
+	theme := getTheme(game.Theme)
 
 	// Iterate through the spots on the game board.
 	for y := 0; y <= 4; y++ {
@@ -316,48 +631,24 @@ func GenerateBoard(game *MinesweeperGame, firstGen, useSpotTypes bool) []discord
 				Disabled: firstGen,
 			}
 
-			typeToUse := spot.DisplayedType
-			if useSpotTypes {
-				typeToUse = spot.Type
-			}
-
-			if useSpotTypes && spot.DisplayedType == minesweeper.Flag && spot.Type == minesweeper.Bomb {
-				typeToUse = minesweeper.Flag
-			}
-
-			if game.Flags&Won != 0 && spot.Type == minesweeper.Bomb {
-				typeToUse = minesweeper.Flag
-			}
+			typeToUse := spot.ShownType(useSpotTypes, game.Flags&Won != 0)
 
 			// Set the properties of the button based on the spot type.
 			switch typeToUse {
 			case minesweeper.Hidden:
-				button.Emoji = discordgo.ComponentEmoji{
-					Name: "invie",
-					ID:   "1112567785076305971",
-				}
+				theme.Hidden.apply(button)
 
 			case minesweeper.Normal:
-				button.Style = discordgo.SecondaryButton
-				button.Label = strconv.Itoa(spot.NearbyBombs)
+				theme.numberCell(spot.NearbyBombs).apply(button)
 
 			case minesweeper.Bomb:
-				button.Emoji = discordgo.ComponentEmoji{
-					Name: "💥",
-				}
-				button.Style = discordgo.DangerButton
+				theme.Bomb.apply(button)
 
 			case minesweeper.Flag:
-				button.Emoji = discordgo.ComponentEmoji{
-					Name: "🚩",
-				}
-				button.Style = discordgo.SuccessButton
+				theme.Flag.apply(button)
 
 			case minesweeper.StartHere:
-				button.Emoji = discordgo.ComponentEmoji{
-					Name: "clickme",
-					ID:   "1119511692825604096",
-				}
+				theme.StartHere.apply(button)
 				button.Disabled = false
 			}
 
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Visibility of the server leaderboard.
+const (
+	LeaderboardPublic  = "public"
+	LeaderboardPrivate = "private"
+	LeaderboardHidden  = "hidden"
+)
+
+var manageServerPermission int64 = discordgo.PermissionManageServer
+var dmPermission = false
+
+func getGuildConfig(guildID string) GuildConfig {
+	if guildID == "" {
+		return GuildConfig{}
+	}
+
+	return getGuildData(guildID).Config
+}
+
+func setGuildConfig(guildID, key string, value interface{}) error {
+	_, err := d.Collection("guilddata").UpdateOne(
+		context.TODO(),
+		bson.D{{Key: "guildID", Value: guildID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "config." + key, Value: value}}}},
+		options.Update().SetUpsert(true),
+	)
+
+	return err
+}
+
+// Channel access modes, a channel is either on the allow list, the deny list or neither.
+const (
+	ChannelAllow   = "allow"
+	ChannelDeny    = "deny"
+	ChannelDefault = "default"
+)
+
+// Moves a channel onto the allow or deny list, or takes it off both.
+func setChannelAccess(guildID, channelID, access string) error {
+	update := bson.D{}
+	switch access {
+	case ChannelAllow:
+		update = append(update,
+			bson.E{Key: "$addToSet", Value: bson.D{{Key: "config.allowedChannels", Value: channelID}}},
+			bson.E{Key: "$pull", Value: bson.D{{Key: "config.deniedChannels", Value: channelID}}},
+		)
+	case ChannelDeny:
+		update = append(update,
+			bson.E{Key: "$addToSet", Value: bson.D{{Key: "config.deniedChannels", Value: channelID}}},
+			bson.E{Key: "$pull", Value: bson.D{{Key: "config.allowedChannels", Value: channelID}}},
+		)
+	default:
+		update = append(update,
+			bson.E{Key: "$pull", Value: bson.D{
+				{Key: "config.allowedChannels", Value: channelID},
+				{Key: "config.deniedChannels", Value: channelID},
+			}},
+		)
+	}
+
+	_, err := d.Collection("guilddata").UpdateOne(
+		context.TODO(),
+		bson.D{{Key: "guildID", Value: guildID}},
+		update,
+		options.Update().SetUpsert(true),
+	)
+
+	return err
+}
+
+// Gets the seconds after which games in the guild end automatically.
+func gameEndAfter(guildID string) int64 {
+	if endAfter := getGuildConfig(guildID).EndAfter; endAfter > 0 {
+		return endAfter
+	}
+
+	return EndAfter
+}
+
+func getChannel(s *discordgo.Session, channelID string) (*discordgo.Channel, error) {
+	if channel, err := s.State.Channel(channelID); err == nil {
+		return channel, nil
+	}
+
+	return s.Channel(channelID)
+}
+
+// Checks whether the config lets games be played in the channel. Threads follow the
+// channel they were started in, unless they are on a list themselves.
+func channelAllowed(s *discordgo.Session, config GuildConfig, channelID string) bool {
+	if isInArray(channelID, config.DeniedChannels) {
+		return false
+	}
+	if len(config.AllowedChannels) == 0 || isInArray(channelID, config.AllowedChannels) {
+		return true
+	}
+
+	channel, err := getChannel(s, channelID)
+	if err != nil || !channel.IsThread() {
+		return false
+	}
+
+	return !isInArray(channel.ParentID, config.DeniedChannels) && isInArray(channel.ParentID, config.AllowedChannels)
+}
+
+// Checks whether games can be started in the channel of the interaction, responding with an
+// ephemeral message if they can't.
+func gameChannelAllowed(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	config := getGuildConfig(i.GuildID)
+	if channelAllowed(s, config, i.ChannelID) {
+		return true
+	}
+
+	content := tr(interactionLocale(i), "channel.denied")
+	if len(config.AllowedChannels) > 0 {
+		content = tr(interactionLocale(i), "channel.deniedTry", mentionChannels(config.AllowedChannels))
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:   1 << 6,
+			Content: content,
+		},
+	})
+	return false
+}
+
+func hasManageServer(i *discordgo.InteractionCreate) bool {
+	return i.Member != nil && i.Member.Permissions&discordgo.PermissionManageServer != 0
+}
+
+// Checks whether the member of the interaction may moderate the games of others.
+func canModerateGames(i *discordgo.InteractionCreate) bool {
+	if i.Member == nil {
+		return false
+	}
+	if hasManageServer(i) {
+		return true
+	}
+
+	modRole := getGuildConfig(i.GuildID).ModRole
+	return modRole != "" && isInArray(modRole, i.Member.Roles)
+}
+
+func mentionChannels(channelIDs []string) string {
+	mentions := make([]string, 0, len(channelIDs))
+	for _, channelID := range channelIDs {
+		mentions = append(mentions, fmt.Sprintf("<#%s>", channelID))
+	}
+
+	return strings.Join(mentions, ", ")
+}
+
+func generateConfigEmbed(locale, guildID string) *discordgo.MessageEmbed {
+	config := getGuildConfig(guildID)
+
+	channels := tr(locale, "config.anyChannel")
+	if len(config.AllowedChannels) > 0 {
+		channels = mentionChannels(config.AllowedChannels)
+	}
+	denied := tr(locale, "config.none")
+	if len(config.DeniedChannels) > 0 {
+		denied = mentionChannels(config.DeniedChannels)
+	}
+
+	timeout := tr(locale, "config.defaultTimeout", EndAfter)
+	if config.EndAfter > 0 {
+		timeout = tr(locale, "config.timeout", config.EndAfter)
+	}
+
+	theme := tr(locale, "config.default")
+	if t, ok := Themes[config.DefaultTheme]; ok {
+		theme = t.Name
+	}
+
+	visibility := config.LeaderboardVisibility
+	if visibility == "" {
+		visibility = LeaderboardPublic
+	}
+
+	modRole := tr(locale, "config.noModerators")
+	if config.ModRole != "" {
+		modRole = fmt.Sprintf("<@&%s>", config.ModRole)
+	}
+
+	return &discordgo.MessageEmbed{
+		Type:  discordgo.EmbedTypeRich,
+		Title: tr(locale, "config.title"),
+		Color: randomEmbedColor(),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: tr(locale, "config.channels"), Value: channels},
+			{Name: tr(locale, "config.deniedChannels"), Value: denied},
+			{Name: tr(locale, "config.threads"), Value: localizedOnOff(locale, config.GameThreads), Inline: true},
+			{Name: tr(locale, "config.endAfter"), Value: timeout, Inline: true},
+			{Name: tr(locale, "config.sarcasm"), Value: localizedOnOff(locale, !config.NoSarcasm), Inline: true},
+			{Name: tr(locale, "config.theme"), Value: theme, Inline: true},
+			{Name: tr(locale, "config.visibility"), Value: tr(locale, "config.visibility."+visibility), Inline: true},
+			{Name: tr(locale, "config.moderators"), Value: modRole, Inline: true},
+			{Name: tr(locale, "config.roleRewards"), Value: describeRoleRewards(locale, config.RoleRewards)},
+		},
+	}
+}
+
+func ConfigCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+	if !hasManageServer(i) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Flags:   1 << 6,
+				Content: tr(interactionLocale(i), "config.noPermission"),
+			},
+		})
+		return
+	}
+
+	subcommand := i.ApplicationCommandData().Options[0]
+	optionMap := mapOptions(subcommand.Options)
+
+	if subcommand.Type == discordgo.ApplicationCommandOptionSubCommandGroup && subcommand.Name == "reward" {
+		embed, err := configRewards(s, i, subcommand.Options[0])
+		if err != nil {
+			cmdError(s, i, err)
+			return
+		}
+		if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Flags:           1 << 6,
+				Embeds:          []*discordgo.MessageEmbed{embed},
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		}); err != nil {
+			cmdError(s, i, err)
+		}
+		return
+	}
+
+	var err error
+	switch subcommand.Name {
+	case "channel":
+		err = setChannelAccess(i.GuildID, optionMap["channel"].ChannelValue(s).ID, optionMap["access"].StringValue())
+	case "threads":
+		err = setGuildConfig(i.GuildID, "gameThreads", optionMap["enabled"].BoolValue())
+	case "timeout":
+		seconds := optionMap["seconds"].IntValue()
+		if seconds < 0 {
+			seconds = 0
+		}
+		err = setGuildConfig(i.GuildID, "endAfter", seconds)
+	case "sarcasm":
+		err = setGuildConfig(i.GuildID, "noSarcasm", !optionMap["enabled"].BoolValue())
+	case "theme":
+		theme := ""
+		if v, ok := optionMap["theme"]; ok {
+			theme = v.StringValue()
+		}
+		err = setGuildConfig(i.GuildID, "defaultTheme", theme)
+	case "leaderboard":
+		err = setGuildConfig(i.GuildID, "leaderboardVisibility", optionMap["visibility"].StringValue())
+	case "modrole":
+		role := ""
+		if v, ok := optionMap["role"]; ok {
+			role = v.RoleValue(s, i.GuildID).ID
+		}
+		err = setGuildConfig(i.GuildID, "modRole", role)
+	}
+	if err != nil {
+		cmdError(s, i, err)
+		return
+	}
+
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:           1 << 6,
+			Embeds:          []*discordgo.MessageEmbed{generateConfigEmbed(interactionLocale(i), i.GuildID)},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	}); err != nil {
+		cmdError(s, i, err)
+	}
+}
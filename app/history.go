@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"main/minesweeper"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Game outcomes as stored in the game history.
+const (
+	OutcomeWon      = "won"
+	OutcomeLost     = "lost"
+	OutcomeGaveUp   = "gaveup"
+	OutcomeTimedOut = "timedout"
+)
+
+func outcomeFromEvent(event int) string {
+	switch event {
+	case minesweeper.Won:
+		return OutcomeWon
+	case minesweeper.Lost:
+		return OutcomeLost
+	case minesweeper.TimedEnd:
+		return OutcomeTimedOut
+	}
+
+	return OutcomeGaveUp
+}
+
+// Stores a finished game in the game history of the user.
+func recordGame(game *MinesweeperGame, event int, endedAt time.Time) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+	record := GameRecord{
+		UserID:     game.UserID,
+		GuildID:    game.GuildID,
+		Difficulty: game.Difficulty,
+		Preset:     game.Preset,
+		Outcome:    outcomeFromEvent(event),
+		StartedAt:  game.StartTime,
+		EndedAt:    endedAt,
+		Clicks:     game.Clicks,
+		FlagClicks: game.FlagClicks,
+		Chords:     game.Chords,
+		Race:       game.Race != nil,
+	}
+	if !game.StartTime.IsZero() {
+		record.Duration = endedAt.Sub(game.StartTime).Seconds()
+	}
+
+	if _, err := d.Collection("games").InsertOne(context.TODO(), record); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func historyFilter(userID, difficulty string) bson.D {
+	filter := bson.D{{
+		Key:   "userID",
+		Value: userID,
+	}}
+	if difficulty != "" {
+		filter = append(filter, bson.E{Key: "difficulty", Value: difficulty})
+	}
+
+	return filter
+}
+
+// Gets the most recent games of a user, newest first. A limit of 0 gets every game.
+func getGameHistory(userID, difficulty string, limit int64) []GameRecord {
+	var records []GameRecord
+	opts := options.Find().SetSort(bson.D{{Key: "endedAt", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	cursor, err := d.Collection("games").Find(context.TODO(), historyFilter(userID, difficulty), opts)
+	if err != nil {
+		fmt.Println(err)
+		return records
+	}
+
+	if err := cursor.All(context.TODO(), &records); err != nil {
+		fmt.Println(err)
+	}
+
+	return records
+}
+
+func countGames(userID, difficulty string) int64 {
+	count, err := d.Collection("games").CountDocuments(context.TODO(), historyFilter(userID, difficulty))
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	return count
+}
+
+// Counts the games of a user per difficulty and outcome.
+func countOutcomes(userID string) map[string]map[string]int64 {
+	counts := make(map[string]map[string]int64)
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: historyFilter(userID, "")}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "difficulty", Value: "$difficulty"},
+				{Key: "outcome", Value: "$outcome"},
+			}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+
+	cursor, err := d.Collection("games").Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		fmt.Println(err)
+		return counts
+	}
+
+	var results []struct {
+		ID struct {
+			Difficulty string `bson:"difficulty"`
+			Outcome    string `bson:"outcome"`
+		} `bson:"_id"`
+		Count int64 `bson:"count"`
+	}
+	if err := cursor.All(context.TODO(), &results); err != nil {
+		fmt.Println(err)
+		return counts
+	}
+
+	for _, result := range results {
+		if counts[result.ID.Difficulty] == nil {
+			counts[result.ID.Difficulty] = make(map[string]int64)
+		}
+		counts[result.ID.Difficulty][result.ID.Outcome] = result.Count
+	}
+
+	return counts
+}
+
+// Averages the time of the won games among the given records, ok is false without any wins.
+func averageWinTime(records []GameRecord) (average float64, ok bool) {
+	var total float64
+	var wins int
+	for _, record := range records {
+		if record.Outcome != OutcomeWon {
+			continue
+		}
+		total += record.Duration
+		wins++
+	}
+
+	if wins == 0 {
+		return 0, false
+	}
+
+	return total / float64(wins), true
+}
+
+// Gets the share of won games among the given records.
+func winRate(records []GameRecord) float64 {
+	if len(records) == 0 {
+		return 0
+	}
+
+	var wins int
+	for _, record := range records {
+		if record.Outcome == OutcomeWon {
+			wins++
+		}
+	}
+
+	return float64(wins) / float64(len(records))
+}
+
+// Splits records ordered newest first into chunks of the given size, ordered oldest first.
+func chunkRecords(records []GameRecord, size int) [][]GameRecord {
+	var chunks [][]GameRecord
+	for end := len(records); end > 0; end -= size {
+		start := end - size
+		if start < 0 {
+			start = 0
+		}
+		chunks = append(chunks, records[start:end])
+	}
+
+	return chunks
+}
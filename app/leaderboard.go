@@ -3,118 +3,208 @@ package main
 import (
 	"context"
 	"fmt"
-	"main/humanizetime"
 	"main/minesweeper"
-	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var autoEditChannel chan struct{}
 
-func orderBySpot(entries []LeaderboardEntry) []LeaderboardEntry {
-	defer func() {
-		if err := recover(); err != nil {
-			handlePanic(err)
-		}
-	}()
-	orderedLeaderboard := make([]LeaderboardEntry, len(entries))
+// Amount of entries shown on a single leaderboard page.
+var leaderboardPageSize = int64(10)
+
+// Identifies a single page of a leaderboard, encoded into the custom ID of the page buttons.
+type leaderboardQuery struct {
+	GuildID    string
+	Difficulty string
+	Metric     string
+	Preset     string
+	Period     string
+	Page       int64
+}
 
-	for _, entry := range entries {
-		if entry.UserID == "" {
-			continue
-		}
-		orderedLeaderboard[entry.Spot] = entry
+// A single rendered row of a leaderboard.
+type leaderboardRow struct {
+	UserID string
+	Value  string
+}
+
+func (q leaderboardQuery) customID(page int64) string {
+	return fmt.Sprintf("leaderboard:%s:%s:%s:%d:%s:%s", q.GuildID, q.Difficulty, q.Metric, page, q.Preset, q.Period)
+}
+
+func parseLeaderboardQuery(customID string) (leaderboardQuery, error) {
+	// The period is last since season names may contain the separator.
+	parts := strings.SplitN(customID, ":", 7)
+	if len(parts) != 7 {
+		return leaderboardQuery{}, fmt.Errorf("invalid leaderboard custom ID %s", customID)
 	}
 
-	return orderedLeaderboard
+	page, err := strconv.ParseInt(parts[4], 10, 64)
+	if err != nil {
+		return leaderboardQuery{}, err
+	}
+
+	return leaderboardQuery{
+		GuildID:    parts[1],
+		Difficulty: parts[2],
+		Metric:     parts[3],
+		Preset:     parts[5],
+		Period:     parts[6],
+		Page:       page,
+	}, nil
 }
 
-func getLeaderboard(guildID string, difficulty int) []LeaderboardEntry {
-	guildData := getGuildData(guildID)
-	leaderboards := guildData.Leaderboard
-	var leaderboard []LeaderboardEntry
+func difficultyFromString(difficultyString string) int {
+	switch difficultyString {
+	case "medium":
+		return minesweeper.Medium
+	case "hard":
+		return minesweeper.Hard
+	case "custom":
+		return minesweeper.Custom
+	}
 
+	return minesweeper.Easy
+}
+
+func difficultyToString(difficulty int) string {
 	switch difficulty {
-	case minesweeper.Easy:
-		leaderboard = leaderboards.Easy
 	case minesweeper.Medium:
-		leaderboard = leaderboards.Medium
+		return "medium"
 	case minesweeper.Hard:
-		leaderboard = leaderboards.Hard
+		return "hard"
+	case minesweeper.Custom:
+		return "custom"
 	}
 
-	orderedLeaderboard := orderBySpot(leaderboard)
+	return "easy"
+}
 
-	return orderedLeaderboard
+// Custom games are only ranked for configurations that have a preset, which is empty for the regular difficulties.
+func leaderboardFilter(guildID string, difficulty int, preset, period string) bson.D {
+	return bson.D{{
+		Key:   "guildID",
+		Value: guildID,
+	}, {
+		Key:   "difficulty",
+		Value: difficulty,
+	}, {
+		Key:   "preset",
+		Value: preset,
+	}, {
+		Key:   "period",
+		Value: period,
+	}}
 }
 
-func addToLeaderboard(guildID string, difficulty int, newEntry LeaderboardEntry) {
-	defer func() {
-		if err := recover(); err != nil {
-			handlePanic(err)
-		}
-	}()
-	currentLeaderboard := getLeaderboard(guildID, difficulty)
-	var dontReorder bool
-	// Remove duplicate ID if new is shorter in length.
-	for index, leaderboardEntry := range currentLeaderboard {
-		if leaderboardEntry.UserID != newEntry.UserID {
-			continue
-		}
-		if leaderboardEntry.Time < newEntry.Time {
-			// Return because the new entry is longer than the past one.
-			return
-		}
-		if leaderboardEntry.Spot == 0 {
-			newEntry.Spot = 0
-			currentLeaderboard[index] = newEntry
-			dontReorder = true
-			break
+// Filters the entries shown on a leaderboard, leaving out users hidden from global leaderboards.
+func visibleLeaderboardFilter(guildID string, difficulty int, preset, period string) bson.D {
+	filter := leaderboardFilter(guildID, difficulty, preset, period)
+	if guildID == "global" {
+		if hidden := getHiddenUsers(); len(hidden) > 0 {
+			filter = append(filter, bson.E{Key: "userId", Value: bson.D{{Key: "$nin", Value: hidden}}})
 		}
-		currentLeaderboard = append(currentLeaderboard[:index], currentLeaderboard[index+1:]...)
 	}
 
-	if !dontReorder {
-		currentLeaderboard = append(currentLeaderboard, newEntry)
-		sort.Slice(currentLeaderboard, func(i, j int) bool {
-			return currentLeaderboard[i].Time < currentLeaderboard[j].Time
-		})
+	return filter
+}
 
-		for spot := range currentLeaderboard {
-			entry := currentLeaderboard[spot]
-			entry.Spot = spot
-			currentLeaderboard[spot] = entry
-		}
+// Gets a single page of the leaderboard along with the total amount of entries.
+func getLeaderboard(guildID string, difficulty int, preset, period string, page int64) ([]LeaderboardEntry, int64, error) {
+	var leaderboard []LeaderboardEntry
+	filter := visibleLeaderboardFilter(guildID, difficulty, preset, period)
+
+	total, err := d.Collection("leaderboardentries").CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return leaderboard, 0, err
 	}
 
-	currentLeaderboard = orderBySpot(currentLeaderboard)
+	cursor, err := d.Collection("leaderboardentries").Find(
+		context.TODO(),
+		filter,
+		options.Find().
+			SetSort(bson.D{{Key: "time", Value: 1}, {Key: "userId", Value: 1}}).
+			SetSkip(page*leaderboardPageSize).
+			SetLimit(leaderboardPageSize),
+	)
+	if err != nil {
+		return leaderboard, total, err
+	}
+
+	err = cursor.All(context.TODO(), &leaderboard)
 
-	if len(currentLeaderboard) > 10 {
-		currentLeaderboard = currentLeaderboard[:10]
+	return leaderboard, total, err
+}
+
+// Gets the 1-indexed rank of a user on a leaderboard, along with the total amount of entries.
+func getLeaderboardRank(guildID string, difficulty int, preset, period string, userID string) (rank int64, total int64, ok bool) {
+	var entry LeaderboardEntry
+	filter := leaderboardFilter(guildID, difficulty, preset, period)
+
+	userFilter := append(filter, bson.E{Key: "userId", Value: userID})
+	if err := d.Collection("leaderboardentries").FindOne(context.TODO(), userFilter).Decode(&entry); err != nil {
+		return
 	}
 
-	newData := getGuildData(guildID)
+	// Users hidden from the leaderboard are ranked among the visible users.
+	filter = visibleLeaderboardFilter(guildID, difficulty, preset, period)
 
-	switch difficulty {
-	case minesweeper.Easy:
-		newData.Leaderboard.Easy = currentLeaderboard
-	case minesweeper.Medium:
-		newData.Leaderboard.Medium = currentLeaderboard
-	case minesweeper.Hard:
-		newData.Leaderboard.Hard = currentLeaderboard
+	// Entries with an equal time are ordered by user ID, same as getLeaderboard.
+	aheadFilter := append(filter, bson.E{Key: "$or", Value: bson.A{
+		bson.D{{Key: "time", Value: bson.D{{Key: "$lt", Value: entry.Time}}}},
+		bson.D{{Key: "time", Value: entry.Time}, {Key: "userId", Value: bson.D{{Key: "$lt", Value: userID}}}},
+	}})
+	ahead, err := d.Collection("leaderboardentries").CountDocuments(context.TODO(), aheadFilter)
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
-	filter := bson.D{{
-		Key:   "guildID",
-		Value: guildID,
-	}}
+	total, err = d.Collection("leaderboardentries").CountDocuments(context.TODO(), filter)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	return ahead + 1, total, true
+}
 
-	data, err := bson.Marshal(newData)
+// Adds the entry to the leaderboard of every period it was achieved in.
+func addToLeaderboard(guildID string, difficulty int, preset string, newEntry LeaderboardEntry) {
+	for _, period := range currentPeriodKeys(newEntry.AchievedAt) {
+		addToLeaderboardPeriod(guildID, difficulty, preset, period, newEntry)
+	}
+}
+
+func addToLeaderboardPeriod(guildID string, difficulty int, preset, period string, newEntry LeaderboardEntry) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+	newEntry.GuildID = guildID
+	newEntry.Difficulty = difficulty
+	newEntry.Preset = preset
+	newEntry.Period = period
+
+	// Only matches the existing entry if the new time is faster, otherwise the upsert
+	// conflicts with the unique index and the old entry is kept.
+	filter := append(leaderboardFilter(guildID, difficulty, preset, period), bson.E{
+		Key:   "userId",
+		Value: newEntry.UserID,
+	}, bson.E{
+		Key:   "time",
+		Value: bson.D{{Key: "$gt", Value: newEntry.Time}},
+	})
+
+	data, err := bson.Marshal(newEntry)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -125,62 +215,238 @@ func addToLeaderboard(guildID string, difficulty int, newEntry LeaderboardEntry)
 		return
 	}
 
-	request := d.Collection("guilddata").FindOneAndUpdate(
+	_, err = d.Collection("leaderboardentries").UpdateOne(
 		context.TODO(),
 		filter,
 		bson.D{{
 			Key:   "$set",
 			Value: update,
 		}},
-		options.FindOneAndUpdate().SetUpsert(true),
+		options.Update().SetUpsert(true),
 	)
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		fmt.Println(err)
+	}
+}
 
-	if err := request.Decode(&newData); err != nil {
+// Moves the leaderboards that used to be stored inline in guild data into their own documents.
+func migrateLegacyLeaderboards() {
+	// Entries from before leaderboard periods existed are all-time entries.
+	if _, err := d.Collection("leaderboardentries").UpdateMany(
+		context.TODO(),
+		bson.D{{Key: "period", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "period", Value: PeriodAllTime}}}},
+	); err != nil {
 		fmt.Println(err)
 	}
+
+	// Entries from before custom presets existed are regular difficulty entries.
+	if _, err := d.Collection("leaderboardentries").UpdateMany(
+		context.TODO(),
+		bson.D{{Key: "preset", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "preset", Value: ""}}}},
+	); err != nil {
+		fmt.Println(err)
+	}
+
+	filter := bson.D{{
+		Key:   "timeLeaderboard",
+		Value: bson.D{{Key: "$exists", Value: true}},
+	}}
+
+	cursor, err := d.Collection("guilddata").Find(context.TODO(), filter)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var guilds []GuildData
+	if err := cursor.All(context.TODO(), &guilds); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, guildData := range guilds {
+		legacy := map[int][]LegacyLeaderboardEntry{
+			minesweeper.Easy:   guildData.Leaderboard.Easy,
+			minesweeper.Medium: guildData.Leaderboard.Medium,
+			minesweeper.Hard:   guildData.Leaderboard.Hard,
+		}
+
+		for difficulty, entries := range legacy {
+			for _, entry := range entries {
+				if entry.UserID == "" {
+					continue
+				}
+				addToLeaderboardPeriod(guildData.GuildID, difficulty, "", PeriodAllTime, LeaderboardEntry{
+					UserID: entry.UserID,
+					Time:   entry.Time,
+				})
+			}
+		}
+
+		if _, err := d.Collection("guilddata").UpdateOne(
+			context.TODO(),
+			bson.D{{Key: "guildID", Value: guildData.GuildID}},
+			bson.D{{Key: "$unset", Value: bson.D{{Key: "timeLeaderboard", Value: ""}}}},
+		); err != nil {
+			fmt.Println(err)
+			continue
+		}
+
+		fmt.Printf("Migrated leaderboard of %s\n", guildData.GuildID)
+	}
 }
 
-func generateLeaderboardEmbed(guildID, guildName, difficultyString string) (discordgo.MessageEmbed, error) {
-	var difficulty int
-	switch difficultyString {
-	case "easy":
-		difficulty = minesweeper.Easy
-	case "medium":
-		difficulty = minesweeper.Medium
-	case "hard":
-		difficulty = minesweeper.Hard
+// Gets the rows of a single leaderboard page along with the total amount of entries.
+func getLeaderboardRows(locale string, query leaderboardQuery) ([]leaderboardRow, int64, error) {
+	var rows []leaderboardRow
+
+	_, friends := friendsScopeOwner(query.GuildID)
+	if query.Metric == "" {
+		query.Metric = MetricTime
+	}
+	if query.Metric != MetricTime || friends {
+		entries, total, err := getMetricLeaderboard(query.GuildID, query.Difficulty, query.Metric, query.Page)
+		for _, entry := range entries {
+			rows = append(rows, leaderboardRow{
+				UserID: entry.UserID,
+				Value:  formatMetricValue(locale, query.Metric, entry),
+			})
+		}
+		return rows, total, err
+	}
+
+	var (
+		leaderboard []LeaderboardEntry
+		total       int64
+		err         error
+	)
+	// Seasons that ended are shown with the final standings they were archived with.
+	if season, ok := archivedSeason(query.Period); ok {
+		leaderboard, total, err = getArchivedLeaderboard(season.Name, query.GuildID, difficultyFromString(query.Difficulty), query.Preset, query.Page)
+	} else {
+		leaderboard, total, err = getLeaderboard(query.GuildID, difficultyFromString(query.Difficulty), query.Preset, query.Period, query.Page)
+	}
+	if err != nil {
+		return rows, total, err
+	}
+
+	for _, entry := range leaderboard {
+		duration, err := time.ParseDuration(fmt.Sprintf("%fs", entry.Time))
+		if err != nil {
+			return rows, total, err
+		}
+
+		rows = append(rows, leaderboardRow{
+			UserID: entry.UserID,
+			Value:  humanizeDuration(locale, duration, 3),
+		})
+	}
+
+	return rows, total, nil
+}
+
+func generateLeaderboardEmbed(locale, guildName string, query leaderboardQuery) (discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	var (
+		difficultyString = query.Difficulty
+		page             = query.Page
+	)
+
+	rows, total, err := getLeaderboardRows(locale, query)
+	if err != nil {
+		fmt.Println(err)
+		return discordgo.MessageEmbed{}, nil, err
+	}
+
+	description := tr(locale, "leaderboard.mode", strings.ToUpper(difficultyString), metricLabel(locale, query.Metric))
+	if query.Preset != "" {
+		description = tr(locale, "leaderboard.preset", query.Preset, metricLabel(locale, query.Metric))
+		if preset, ok := getPresetByKey(query.Preset); ok {
+			description = tr(locale, "leaderboard.presetSettings", preset.Name, preset.settingsString(locale), metricLabel(locale, query.Metric))
+		}
+	}
+	if query.Metric == MetricTime || query.Metric == "" {
+		description = fmt.Sprintf("%s - %s", description, periodLabel(locale, query.Period))
+	}
+	if query.Metric == MetricAchievements || query.Metric == MetricAchievementScore {
+		description = metricLabel(locale, query.Metric)
 	}
 
-	leaderboard := getLeaderboard(guildID, difficulty)
+	pages := (total + leaderboardPageSize - 1) / leaderboardPageSize
+	if pages < 1 {
+		pages = 1
+	}
 
 	embed := discordgo.MessageEmbed{
 		Type:        discordgo.EmbedTypeRich,
-		Title:       fmt.Sprintf("%s Leaderboard", guildName),
-		Description: fmt.Sprintf("Leaderboard for **%s** mode", strings.ToUpper(difficultyString)),
+		Title:       tr(locale, "leaderboard.title", guildName),
+		Description: description,
 		Color:       randomEmbedColor(),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: trPlural(locale, "leaderboard.footer", float64(total), page+1, pages, formatNumber(total)),
+		},
 	}
 
-	for _, entry := range leaderboard {
-		userString := entry.UserID
-		user, err := s.User(entry.UserID, RequestOption)
+	for index, row := range rows {
+		userString := row.UserID
+		user, err := getUser(row.UserID, false)
 		if err == nil {
 			userString = user.Username
 		}
 
-		duration, err := time.ParseDuration(fmt.Sprintf("%fs", entry.Time))
-		if err != nil {
-			fmt.Println(err)
-			return discordgo.MessageEmbed{}, err
-		}
-
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-			Name:   userString,
-			Value:  humanizetime.HumanizeDuration(duration, 3),
+			Name:   fmt.Sprintf("#%d %s", page*leaderboardPageSize+int64(index)+1, userString),
+			Value:  row.Value,
 			Inline: false,
 		})
 	}
 
-	return embed, nil
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					CustomID: query.customID(page - 1),
+					Label:    "←",
+					Style:    discordgo.PrimaryButton,
+					Disabled: page <= 0,
+				},
+				discordgo.Button{
+					CustomID: query.customID(page + 1),
+					Label:    "→",
+					Style:    discordgo.PrimaryButton,
+					Disabled: page+1 >= pages,
+				},
+			},
+		},
+	}
+
+	return embed, components, nil
+}
+
+// Gets the display name used in the leaderboard title.
+func leaderboardGuildName(locale, guildID string) string {
+	if guildID == "global" {
+		return guildID
+	}
+	if ownerID, ok := friendsScopeOwner(guildID); ok {
+		name := ownerID
+		if user, err := getUser(ownerID, false); err == nil {
+			name = user.Username
+		}
+		return tr(locale, "leaderboard.friendsName", name)
+	}
+
+	guild, err := s.State.Guild(guildID)
+	if err != nil {
+		guild, err = s.Guild(guildID, RequestOption)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return guildID
+	}
+
+	return tr(locale, "leaderboard.guildName", guild.Name)
 }
 
 func editConfiguredMessages() {
@@ -194,17 +460,18 @@ func editConfiguredMessages() {
 			continue
 		}
 
-		var difficultyString string
-		switch message.Difficulty {
-		case minesweeper.Easy:
-			difficultyString = "easy"
-		case minesweeper.Medium:
-			difficultyString = "medium"
-		case minesweeper.Hard:
-			difficultyString = "hard"
+		period, err := periodKey(message.Period, time.Now())
+		if err != nil {
+			// Keep the last standings up until the next season starts.
+			continue
 		}
 
-		embed, err := generateLeaderboardEmbed(guild.ID, guild.Name, difficultyString)
+		embed, _, err := generateLeaderboardEmbed(string(guild.PreferredLocale), guild.Name, leaderboardQuery{
+			GuildID:    guild.ID,
+			Difficulty: difficultyToString(message.Difficulty),
+			Metric:     MetricTime,
+			Period:     period,
+		})
 		if err != nil {
 			removeLeaderboardMessage(message.MessageID)
 			fmt.Println(err)
@@ -230,7 +497,9 @@ func startAutoEdit() {
 		for {
 			select {
 			case <-ticker.C:
+				closeEndedSeasons()
 				editConfiguredMessages()
+				syncAllLeaderboardRewards()
 			case <-autoEditChannel:
 				ticker.Stop()
 				return
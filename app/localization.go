@@ -0,0 +1,142 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"main/humanizetime"
+	"main/localization"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Message catalogs, one file per Discord locale named after the locale.
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// Catalog of every user facing message, missing translations fall back to English.
+var Messages = localization.New(string(discordgo.EnglishUS))
+
+// Loads the message catalogs, then localizes the commands with them.
+func loadLocales() {
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, file := range files {
+		data, err := localeFiles.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			panic(err)
+		}
+		if err := Messages.Load(strings.TrimSuffix(file.Name(), ".json"), data); err != nil {
+			panic(err)
+		}
+	}
+
+	localizeCommands()
+	fmt.Printf("Loaded %d locales\n", len(Messages.Locales()))
+}
+
+// Gets the locale to answer an interaction in, the one of the user or else the one of the guild.
+func interactionLocale(i *discordgo.InteractionCreate) string {
+	if i.Locale != "" {
+		return string(i.Locale)
+	}
+	if i.GuildLocale != nil && *i.GuildLocale != "" {
+		return string(*i.GuildLocale)
+	}
+
+	return Messages.Fallback
+}
+
+// An error the user can fix, shown to them in their language by cmdError. Its own message is the
+// one of the fallback locale, for the logs.
+type localizedError struct {
+	id   string
+	args []interface{}
+}
+
+func (e *localizedError) Error() string {
+	return tr(Messages.Fallback, e.id, e.args...)
+}
+
+// Gets the error in the language of the locale, other errors stay as they are.
+func localizeError(locale string, err error) error {
+	var localized *localizedError
+	if errors.As(err, &localized) {
+		return errors.New(tr(locale, localized.id, localized.args...))
+	}
+
+	return err
+}
+
+func tr(locale, id string, args ...interface{}) string {
+	return Messages.Get(locale, id, args...)
+}
+
+func trPlural(locale, id string, count float64, args ...interface{}) string {
+	return Messages.Plural(locale, id, count, args...)
+}
+
+// Writes out a duration in the language of the locale.
+func humanizeDuration(locale string, duration time.Duration, secondPrecision int) string {
+	return humanizetime.Format(duration, humanizetime.Options{
+		Locale: humanizetime.Locale{
+			Unit: func(unit string, value float64) string {
+				return trPlural(locale, "time."+unit, value)
+			},
+			Abbreviations: humanizetime.GetLocale(locale).Abbreviations,
+			Separator:     tr(locale, "time.separator"),
+			And:           tr(locale, "time.and"),
+			Decimal:       tr(locale, "time.decimal"),
+		},
+		Precision: secondPrecision,
+	})
+}
+
+func localizations(id string) map[discordgo.Locale]string {
+	translations := Messages.Translations(id)
+	if len(translations) == 0 {
+		return nil
+	}
+
+	localized := make(map[discordgo.Locale]string, len(translations))
+	for locale, text := range translations {
+		localized[discordgo.Locale(locale)] = text
+	}
+
+	return localized
+}
+
+// Fills the name and description localizations of every command, option and choice. Their
+// messages are keyed by the path of names leading to them, like command.stats.chart.type.name.
+func localizeCommands() {
+	var walk func(prefix string, options []*discordgo.ApplicationCommandOption)
+	walk = func(prefix string, options []*discordgo.ApplicationCommandOption) {
+		for _, option := range options {
+			id := prefix + "." + option.Name
+			option.NameLocalizations = localizations(id + ".name")
+			option.DescriptionLocalizations = localizations(id + ".description")
+			for _, choice := range option.Choices {
+				choice.NameLocalizations = localizations(fmt.Sprintf("%s.choice.%v", id, choice.Value))
+			}
+			walk(id, option.Options)
+		}
+	}
+
+	for _, command := range Commands {
+		id := "command." + command.Name
+		if names := localizations(id + ".name"); names != nil {
+			command.NameLocalizations = &names
+		}
+		if descriptions := localizations(id + ".description"); descriptions != nil {
+			command.DescriptionLocalizations = &descriptions
+		}
+		walk(id, command.Options)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"syscall"
 	"time"
 
+	"main/boardimage"
 	"main/minesweeper"
 
 	"github.com/bwmarrin/discordgo"
@@ -24,26 +25,45 @@ import (
 type MinesweeperGame struct {
 	GuildID      string
 	ChannelID    string
+	ThreadID     string
 	BoardID      string
 	FlagID       string
 	UserID       string
 	Difficulty   string
+	Preset       string
+	Theme        string
+	Locale       string
 	Flags        int64
+	Clicks       int64
+	FlagClicks   int64
+	Chords       int64
 	StartTime    time.Time
-	Achievements map[int]Achievement
+	Achievements *achievementTracker
 	Game         *minesweeper.Game
 	EndGameChan  *chan struct{}
+	// Closed by HandleGameEnd once the game is over and removed from Games.
+	Removed     chan struct{}
+	Race        *Race
+	Interaction *discordgo.Interaction
+	// Held while the board or the counters of the game change, and while they are read outside
+	// of the handlers changing them.
+	mutex sync.Mutex
 }
 
 var s *discordgo.Session
 var c *mongo.Client
 var d *mongo.Database
 var Games = make(map[string]*MinesweeperGame)
+
+// Guards Games, which handlers and game timers use from goroutines of their own. Use getGame,
+// gameByFlagID, addGame and removeGame instead of the map.
+var gamesMutex sync.RWMutex
 var ChannelMutex = make(map[string]*sync.Mutex)
 var BoardPositionRegex = regexp.MustCompile(`boardx(\d+)y(\d+)`)
+var LeaderboardPageRegex = regexp.MustCompile(`^leaderboard:`)
 var MessageLinkRegex = regexp.MustCompile(`(?:http(?:s)?://)(?:(?:canary|ptb).)?discord.com/channels/(\d+|@me)/(\d+)/(\d+)`)
-var UserStatsFormatString = "**Wins:** %d\n**Losses:** %d\n**Winstreak:** %d\n**Personal Best:** %s\n**Personal Worst:** %s"
 var Admins = make(map[string]bool)
+var BoardImageTheme = boardimage.Discord
 var EndAfter int64
 var TGGStatsURI string
 
@@ -81,6 +101,11 @@ func main() {
 	d = c.Database("minesweeper")
 	fmt.Println("Verifying all collections...")
 	CollectionCheck(d)
+	fmt.Println("Verifying all indexes...")
+	IndexCheck(d)
+	fmt.Println("Migrating legacy leaderboards...")
+	migrateLegacyLeaderboards()
+	backfillUserGuilds()
 	// Config setup.
 	fmt.Println("Setting up admin map...")
 	for _, userID := range strings.Split(os.Getenv("ADMINS"), " ") {
@@ -95,6 +120,20 @@ func main() {
 		EndAfter = int64(0)
 	}
 
+	if minGames, err := strconv.ParseInt(os.Getenv("WINRATE_MIN_GAMES"), 10, 64); err == nil {
+		WinRateMinGames = minGames
+	}
+
+	RateSoloGames = os.Getenv("RATE_SOLO_GAMES") == "true"
+
+	if theme, ok := boardimage.Themes[os.Getenv("BOARD_IMAGE_THEME")]; ok {
+		BoardImageTheme = theme
+	}
+	loadThemes()
+	loadAchievements()
+	loadLocales()
+	loadShop()
+
 	// Bot setup.
 	fmt.Println("Starting the bot...")
 	BotInit()
@@ -110,6 +149,9 @@ func main() {
 	fmt.Println("Starting leaderboard edit ticker...")
 	startAutoEdit()
 
+	fmt.Println("Starting matchmaker...")
+	startMatchmaker()
+
 	if auth := os.Getenv("TOPGG_BOT_TOKEN"); auth != "" {
 		fmt.Println("Starting top.gg auto stats uploader...")
 		startTGGUpdater(auth)
@@ -141,6 +183,22 @@ func isInIntArray(value int, array []int) bool {
 	return false
 }
 
+// Formats a number with thousands separators, i.e. 2410 -> 2,410.
+func formatNumber(n int64) string {
+	str := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		str = str[1:]
+	}
+
+	for i := len(str) - 3; i > 0; i -= 3 {
+		str = str[:i] + "," + str[i:]
+	}
+
+	return sign + str
+}
+
 func randomEmbedColor() int {
 	r := rand.Intn(255)
 	g := rand.Intn(255)
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Leaderboard metrics selectable by users.
+const (
+	MetricTime         = "time"
+	MetricWins         = "wins"
+	MetricWinRate      = "winrate"
+	MetricStreak       = "streak"
+	MetricBestStreak   = "beststreak"
+	MetricAchievements = "achievements"
+	// Unlocked achievements weighted by how rare they are.
+	MetricAchievementScore = "achievementscore"
+	MetricRating           = "rating"
+)
+
+var ErrMetricPeriod = &localizedError{id: "error.metricPeriod"}
+
+// Minimum amount of games a user needs to have played to appear on the win rate leaderboard.
+var WinRateMinGames int64 = 10
+
+type MetricEntry struct {
+	UserID string  `bson:"userID"`
+	Value  float64 `bson:"value"`
+	Games  int64   `bson:"games"`
+}
+
+type metricPage struct {
+	Total []struct {
+		Count int64 `bson:"count"`
+	} `bson:"total"`
+	Entries []MetricEntry `bson:"entries"`
+}
+
+func metricLabel(locale, metric string) string {
+	switch metric {
+	case MetricWins:
+		return tr(locale, "metric.wins")
+	case MetricWinRate:
+		return tr(locale, "metric.winRate", WinRateMinGames)
+	case MetricStreak:
+		return tr(locale, "metric.winStreak")
+	case MetricBestStreak:
+		return tr(locale, "metric.bestStreak")
+	case MetricAchievements:
+		return tr(locale, "metric.achievements")
+	case MetricAchievementScore:
+		return tr(locale, "metric.achievementScore")
+	case MetricRating:
+		return tr(locale, "metric.rating")
+	}
+
+	return tr(locale, "metric.time")
+}
+
+func formatMetricValue(locale, metric string, entry MetricEntry) string {
+	switch metric {
+	case MetricWins:
+		return trPlural(locale, "metric.winsValue", entry.Value, formatNumber(int64(entry.Value)))
+	case MetricWinRate:
+		return trPlural(locale, "metric.winRateValue", float64(entry.Games), entry.Value*100, formatNumber(entry.Games))
+	case MetricStreak, MetricBestStreak:
+		return trPlural(locale, "metric.streakValue", entry.Value, formatNumber(int64(entry.Value)))
+	case MetricAchievements:
+		return trPlural(locale, "metric.achievementsValue", entry.Value, formatNumber(int64(entry.Value)))
+	case MetricAchievementScore:
+		return trPlural(locale, "metric.achievementScoreValue", entry.Value, formatNumber(int64(entry.Value)))
+	case MetricRating:
+		return fmt.Sprintf("%.0f", entry.Value)
+	case MetricTime:
+		return humanizeDuration(locale, secondsDuration(entry.Value), 3)
+	}
+
+	return fmt.Sprintf("%v", entry.Value)
+}
+
+// Builds the aggregation expression computing the metric of a user document.
+func metricExpression(metric, difficulty string) (value interface{}, games interface{}) {
+	field := func(name string) interface{} {
+		return bson.D{{
+			Key:   "$ifNull",
+			Value: bson.A{fmt.Sprintf("$difficulties.%s.%s", difficulty, name), 0},
+		}}
+	}
+	games = bson.D{{Key: "$add", Value: bson.A{field("wins"), field("losses")}}}
+
+	switch metric {
+	case MetricTime:
+		// Only friend leaderboards rank times by user data, the others use their own entries.
+		value = field("PB")
+	case MetricWins:
+		value = field("wins")
+	case MetricWinRate:
+		value = bson.D{{
+			Key: "$cond",
+			Value: bson.A{
+				bson.D{{Key: "$gt", Value: bson.A{games, 0}}},
+				bson.D{{Key: "$divide", Value: bson.A{field("wins"), games}}},
+				0,
+			},
+		}}
+	case MetricStreak:
+		value = field("streak")
+	case MetricBestStreak:
+		// Users who have not won since best streaks were recorded only have their current streak.
+		value = bson.D{{Key: "$max", Value: bson.A{field("bestStreak"), field("streak")}}}
+	case MetricRating:
+		// Unrated players have no rating yet and are left out.
+		value = field("rating")
+	case MetricAchievements:
+		value = bson.D{{
+			Key:   "$size",
+			Value: bson.D{{Key: "$ifNull", Value: bson.A{"$achievements", bson.A{}}}},
+		}}
+	case MetricAchievementScore:
+		value = achievementScoreExpression()
+	}
+
+	return
+}
+
+// Gets a single page of a metric leaderboard along with the total amount of entries.
+func getMetricLeaderboard(guildID, difficulty, metric string, page int64) ([]MetricEntry, int64, error) {
+	value, games := metricExpression(metric, difficulty)
+
+	var pipeline bson.A
+	if ownerID, ok := friendsScopeOwner(guildID); ok {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.D{{Key: "userID", Value: bson.D{{Key: "$in", Value: friendGroup(ownerID)}}}}}})
+	} else if guildID != "global" {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.D{{Key: "guilds", Value: guildID}}}})
+	} else {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.D{{Key: "settings.hideFromGlobal", Value: bson.D{{Key: "$ne", Value: true}}}}}})
+	}
+
+	pipeline = append(pipeline, bson.D{{
+		Key: "$project",
+		Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "userID", Value: 1},
+			{Key: "value", Value: value},
+			{Key: "games", Value: games},
+		},
+	}})
+
+	match := bson.D{{Key: "value", Value: bson.D{{Key: "$gt", Value: 0}}}}
+	if metric == MetricWinRate {
+		match = append(match, bson.E{Key: "games", Value: bson.D{{Key: "$gte", Value: WinRateMinGames}}})
+	}
+
+	// Times are ranked fastest first, everything else highest first.
+	order := -1
+	if metric == MetricTime {
+		order = 1
+	}
+
+	pipeline = append(pipeline,
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "value", Value: order}, {Key: "userID", Value: 1}}}},
+		bson.D{{
+			Key: "$facet",
+			Value: bson.D{
+				{Key: "total", Value: bson.A{bson.D{{Key: "$count", Value: "count"}}}},
+				{Key: "entries", Value: bson.A{
+					bson.D{{Key: "$skip", Value: page * leaderboardPageSize}},
+					bson.D{{Key: "$limit", Value: leaderboardPageSize}},
+				}},
+			},
+		}},
+	)
+
+	cursor, err := d.Collection("userdata").Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var results []metricPage
+	if err := cursor.All(context.TODO(), &results); err != nil {
+		return nil, 0, err
+	}
+
+	if len(results) == 0 || len(results[0].Total) == 0 {
+		return nil, 0, nil
+	}
+
+	return results[0].Entries, results[0].Total[0].Count, nil
+}
+
+// Adds guilds to the user data of users that played before guilds were tracked, based on their leaderboard entries.
+func backfillUserGuilds() {
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "guildID", Value: bson.D{{Key: "$ne", Value: "global"}}}}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$userId"},
+			{Key: "guilds", Value: bson.D{{Key: "$addToSet", Value: "$guildID"}}},
+		}}},
+	}
+
+	cursor, err := d.Collection("leaderboardentries").Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var results []struct {
+		UserID string   `bson:"_id"`
+		Guilds []string `bson:"guilds"`
+	}
+	if err := cursor.All(context.TODO(), &results); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, result := range results {
+		filter := bson.D{
+			{Key: "userID", Value: result.UserID},
+			{Key: "guilds", Value: bson.D{{Key: "$exists", Value: false}}},
+		}
+		if _, err := d.Collection("userdata").UpdateOne(
+			context.TODO(),
+			filter,
+			bson.D{{Key: "$set", Value: bson.D{{Key: "guilds", Value: result.Guilds}}}},
+		); err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
+// Builds the aggregation expression summing the points of the achievements a user unlocked.
+func achievementScoreExpression() interface{} {
+	rarity := getAchievementRarity()
+
+	branches := bson.A{}
+	for _, ID := range AchievementOrder {
+		branches = append(branches, bson.D{
+			{Key: "case", Value: bson.D{{Key: "$eq", Value: bson.A{"$$id", ID}}}},
+			{Key: "then", Value: achievementPoints(rarity[ID])},
+		})
+	}
+
+	// Unlocks from before timestamps were stored are only the ID.
+	ids := bson.D{{Key: "$map", Value: bson.D{
+		{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$achievements", bson.A{}}}}},
+		{Key: "as", Value: "unlock"},
+		{Key: "in", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$$unlock.id", "$$unlock"}}}},
+	}}}
+
+	return bson.D{{Key: "$sum", Value: bson.D{{Key: "$map", Value: bson.D{
+		{Key: "input", Value: ids},
+		{Key: "as", Value: "id"},
+		{Key: "in", Value: bson.D{{Key: "$switch", Value: bson.D{
+			{Key: "branches", Value: branches},
+			{Key: "default", Value: 0},
+		}}}},
+	}}}}}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Placements on a leaderboard are only announced up to this rank.
+const announcedPlacements = 10
+
+// Most users a user can subscribe to.
+const maxSubscriptions = 25
+
+// A new all-time placement of a player on a leaderboard.
+type Placement struct {
+	GuildID string
+	Rank    int64
+	// Whether the player moved up, or got on the leaderboard for the first time.
+	Improved bool
+	// The entry the player passed, if any.
+	Beaten *LeaderboardEntry
+	Margin float64
+}
+
+// Adds the entry to a leaderboard, getting where it placed the player and who they passed.
+func placeOnLeaderboard(guildID string, difficulty int, preset string, entry LeaderboardEntry) Placement {
+	oldRank, _, hadRank := getLeaderboardRank(guildID, difficulty, preset, PeriodAllTime, entry.UserID)
+	addToLeaderboard(guildID, difficulty, preset, entry)
+
+	placement := Placement{GuildID: guildID}
+	rank, _, ok := getLeaderboardRank(guildID, difficulty, preset, PeriodAllTime, entry.UserID)
+	if !ok {
+		return placement
+	}
+	placement.Rank = rank
+	placement.Improved = !hadRank || rank < oldRank
+	if !placement.Improved {
+		return placement
+	}
+
+	// The entry right behind the player is the one they just passed. Entries with an equal
+	// time are ordered by user ID, same as getLeaderboard.
+	filter := append(visibleLeaderboardFilter(guildID, difficulty, preset, PeriodAllTime), bson.E{Key: "$or", Value: bson.A{
+		bson.D{{Key: "time", Value: bson.D{{Key: "$gt", Value: entry.Time}}}},
+		bson.D{{Key: "time", Value: entry.Time}, {Key: "userId", Value: bson.D{{Key: "$gt", Value: entry.UserID}}}},
+	}})
+	var beaten LeaderboardEntry
+	err := d.Collection("leaderboardentries").FindOne(
+		context.TODO(),
+		filter,
+		options.FindOne().SetSort(bson.D{{Key: "time", Value: 1}, {Key: "userId", Value: 1}}),
+	).Decode(&beaten)
+	if err == nil {
+		placement.Beaten = &beaten
+		placement.Margin = beaten.Time - entry.Time
+	}
+
+	return placement
+}
+
+// Describes the new personal best and leaderboard placements of a win, empty if there are none.
+func describeRecords(locale string, oldPB, newPB float64, placements []Placement) string {
+	var lines []string
+	if newPB > 0 && (oldPB == 0 || newPB < oldPB) {
+		if oldPB == 0 {
+			lines = append(lines, tr(locale, "game.firstPB"))
+		} else {
+			lines = append(lines, tr(locale, "game.newPB",
+				humanizeDuration(locale, secondsDuration(oldPB-newPB), 2),
+				humanizeDuration(locale, secondsDuration(oldPB), 3)))
+		}
+	}
+
+	for _, placement := range placements {
+		if !placement.Improved || placement.Rank > announcedPlacements {
+			continue
+		}
+
+		line := tr(locale, "game.placementGuild", placement.Rank)
+		if placement.GuildID == "global" {
+			line = tr(locale, "game.placementGlobal", placement.Rank)
+		}
+		if placement.Beaten != nil {
+			line = tr(locale, "game.placementBeat", line, placement.Beaten.UserID, humanizeDuration(locale, secondsDuration(placement.Margin), 2))
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// Sends a direct message to the user, for notifications that don't belong to an interaction.
+func notifyUser(s *discordgo.Session, userID, content string) {
+	channel, err := s.UserChannelCreate(userID)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if _, err := s.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+		Content:         content,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	}); err != nil {
+		// Users that don't accept direct messages just miss the notification.
+		fmt.Println(err)
+	}
+}
+
+// Tells the users passed on a leaderboard, and the subscribers of the player whose personal best
+// got beaten, if they want to know.
+func notifyRecords(s *discordgo.Session, game *MinesweeperGame, oldPB, newPB float64, placements []Placement) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+
+	notified := map[string]bool{game.UserID: true}
+	for _, placement := range placements {
+		if placement.Beaten == nil || placement.Rank > announcedPlacements || notified[placement.Beaten.UserID] {
+			continue
+		}
+		userData := getUserData(placement.Beaten.UserID)
+		if !userData.Settings.BeatenDMs {
+			continue
+		}
+		notified[placement.Beaten.UserID] = true
+
+		locale := userData.locale()
+		where := tr(locale, "notify.guild", leaderboardGuildName(locale, placement.GuildID))
+		if placement.GuildID == "global" {
+			where = tr(locale, "notify.global")
+		}
+		notifyUser(s, placement.Beaten.UserID, tr(locale, "notify.passed",
+			game.UserID,
+			where,
+			strings.ToUpper(game.Difficulty),
+			humanizeDuration(locale, secondsDuration(placement.Margin), 2),
+			placement.Rank+1))
+	}
+
+	// Subscribers only hear about personal bests on the regular difficulties.
+	if game.Difficulty == "custom" || newPB <= 0 || (oldPB > 0 && newPB >= oldPB) {
+		return
+	}
+	if getUserSettings(game.UserID).HideFromGlobal {
+		return
+	}
+
+	// Only subscribers the player wasn't already faster than got beaten by this game.
+	pb := bson.D{{Key: "$gt", Value: newPB}}
+	if oldPB > 0 {
+		pb = append(pb, bson.E{Key: "$lte", Value: oldPB})
+	}
+	cursor, err := d.Collection("userdata").Find(context.TODO(), bson.D{
+		{Key: "subscriptions", Value: game.UserID},
+		{Key: "difficulties." + game.Difficulty + ".PB", Value: pb},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	var subscribers []UserData
+	if err := cursor.All(context.TODO(), &subscribers); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, subscriber := range subscribers {
+		if notified[subscriber.UserID] {
+			continue
+		}
+		notified[subscriber.UserID] = true
+
+		locale := subscriber.locale()
+		notifyUser(s, subscriber.UserID, tr(locale, "notify.beatPB",
+			game.UserID,
+			strings.ToUpper(game.Difficulty),
+			humanizeDuration(locale, secondsDuration(subscriber.Difficulties[game.Difficulty].PB), 3),
+			humanizeDuration(locale, secondsDuration(newPB), 3)))
+	}
+}
+
+// Gets the locale to send notifications to the user in, the one of the last game they played.
+func (userData UserData) locale() string {
+	if userData.Locale != "" {
+		return userData.Locale
+	}
+
+	return Messages.Fallback
+}
+
+func subscribe(userID, targetID string) error {
+	_, err := d.Collection("userdata").UpdateOne(
+		context.TODO(),
+		bson.D{{Key: "userID", Value: userID}},
+		bson.D{{Key: "$addToSet", Value: bson.D{{Key: "subscriptions", Value: targetID}}}},
+		options.Update().SetUpsert(true),
+	)
+
+	return err
+}
+
+func unsubscribe(userID, targetID string) error {
+	_, err := d.Collection("userdata").UpdateOne(
+		context.TODO(),
+		bson.D{{Key: "userID", Value: userID}},
+		bson.D{{Key: "$pull", Value: bson.D{{Key: "subscriptions", Value: targetID}}}},
+	)
+
+	return err
+}
+
+func describeSubscriptions(locale string, subscriptions []string) string {
+	if len(subscriptions) == 0 {
+		return tr(locale, "subscribe.none")
+	}
+
+	mentions := make([]string, 0, len(subscriptions))
+	for _, userID := range subscriptions {
+		mentions = append(mentions, fmt.Sprintf("<@%s>", userID))
+	}
+
+	return tr(locale, "subscribe.list", strings.Join(mentions, ", "))
+}
+
+func SubscribeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+	userID, _ := getUserID(i)
+	locale := interactionLocale(i)
+
+	subcommand := i.ApplicationCommandData().Options[0]
+	optionMap := mapOptions(subcommand.Options)
+
+	var content string
+	switch subcommand.Name {
+	case "add":
+		target := optionMap["user"].UserValue(s)
+		subscriptions := getUserData(userID).Subscriptions
+		switch {
+		case target.ID == userID || target.Bot:
+			content = tr(locale, "subscribe.invalid")
+		case isInArray(target.ID, subscriptions):
+			content = tr(locale, "subscribe.already", target.ID)
+		case len(subscriptions) >= maxSubscriptions:
+			content = tr(locale, "subscribe.full", maxSubscriptions)
+		default:
+			if err := subscribe(userID, target.ID); err != nil {
+				cmdError(s, i, err)
+				return
+			}
+			content = tr(locale, "subscribe.added", target.ID)
+		}
+	case "remove":
+		target := optionMap["user"].UserValue(s)
+		if err := unsubscribe(userID, target.ID); err != nil {
+			cmdError(s, i, err)
+			return
+		}
+		content = tr(locale, "subscribe.removed", target.ID)
+	case "list":
+		content = describeSubscriptions(locale, getUserData(userID).Subscriptions)
+	}
+
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:           1 << 6,
+			Content:         content,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	}); err != nil {
+		cmdError(s, i, err)
+	}
+}
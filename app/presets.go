@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Gets the key identifying a custom game configuration, shared by every game with the same settings.
+func presetKey(bombs int64, allowSurroundingBombs, noStartSpot bool) string {
+	return fmt.Sprintf("custom-%d-%t-%t", bombs, allowSurroundingBombs, noStartSpot)
+}
+
+// Clamps a custom bomb count to what fits on the board.
+func clampBombs(bombs int64) int64 {
+	if bombs <= 0 {
+		bombs = 1
+	}
+	if bombs >= 24 {
+		bombs = 24
+	}
+
+	return bombs
+}
+
+// Describes the settings of a preset, i.e. "20 bombs, no start spot".
+func (p CustomPreset) settingsString(locale string) string {
+	settings := []string{trPlural(locale, "preset.bombs", float64(p.Bombs), p.Bombs)}
+	if p.NoStartSpot {
+		settings = append(settings, tr(locale, "preset.noStartSpot"))
+	}
+	if p.SurroundingBombs && !p.NoStartSpot {
+		settings = append(settings, tr(locale, "preset.surroundingBombs"))
+	}
+
+	return strings.Join(settings, ", ")
+}
+
+func getPreset(name string) (CustomPreset, bool) {
+	var preset CustomPreset
+	filter := bson.D{{
+		Key:   "name",
+		Value: strings.ToLower(name),
+	}}
+	if err := d.Collection("custompresets").FindOne(context.TODO(), filter).Decode(&preset); err != nil {
+		return preset, false
+	}
+
+	return preset, true
+}
+
+func getPresetByKey(key string) (CustomPreset, bool) {
+	var preset CustomPreset
+	filter := bson.D{{
+		Key:   "key",
+		Value: key,
+	}}
+	if err := d.Collection("custompresets").FindOne(context.TODO(), filter).Decode(&preset); err != nil {
+		return preset, false
+	}
+
+	return preset, true
+}
+
+func getPresets() []CustomPreset {
+	var presets []CustomPreset
+	cursor, err := d.Collection("custompresets").Find(
+		context.TODO(),
+		bson.D{},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}),
+	)
+	if err != nil {
+		fmt.Println(err)
+		return presets
+	}
+
+	if err := cursor.All(context.TODO(), &presets); err != nil {
+		fmt.Println(err)
+	}
+
+	return presets
+}
+
+// Creates or renames the preset for a configuration, each configuration has at most one name.
+func setPreset(preset CustomPreset) error {
+	preset.Name = strings.ToLower(preset.Name)
+	preset.Key = presetKey(preset.Bombs, preset.SurroundingBombs, preset.NoStartSpot)
+
+	if existing, ok := getPreset(preset.Name); ok && existing.Key != preset.Key {
+		return fmt.Errorf("the name %s is already used for %s", preset.Name, existing.settingsString(Messages.Fallback))
+	}
+
+	data, err := bson.Marshal(preset)
+	if err != nil {
+		return err
+	}
+
+	var update bson.M
+	if err := bson.Unmarshal(data, &update); err != nil {
+		return err
+	}
+
+	_, err = d.Collection("custompresets").UpdateOne(
+		context.TODO(),
+		bson.D{{Key: "key", Value: preset.Key}},
+		bson.D{{Key: "$set", Value: update}},
+		options.Update().SetUpsert(true),
+	)
+
+	return err
+}
+
+func deletePreset(name string) bool {
+	result, err := d.Collection("custompresets").DeleteOne(context.TODO(), bson.D{{Key: "name", Value: strings.ToLower(name)}})
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+
+	return result.DeletedCount > 0
+}
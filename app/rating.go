@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"main/minesweeper"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Rating every player starts out with.
+var DefaultRating = float64(1200)
+
+// Ratings of the boards themselves, used when solo games are rated.
+var BoardRatings = map[string]float64{
+	"easy":   1000,
+	"medium": 1200,
+	"hard":   1400,
+}
+
+// Whether solo games update the rating of a player.
+var RateSoloGames bool
+
+// How long a player stays in the matchmaking queue before giving up.
+var matchmakingTimeout = 5 * time.Minute
+
+type queuedPlayer struct {
+	UserID      string
+	Rating      float64
+	JoinedAt    time.Time
+	Interaction *discordgo.InteractionCreate
+}
+
+// Map guild and difficulty to the players waiting for an opponent.
+var MatchmakingQueues = make(map[string][]*queuedPlayer)
+var matchmakingMutex sync.Mutex
+var matchmakingChannel chan struct{}
+
+// Race between two players on the same board, the first one to win takes it.
+type Race struct {
+	Difficulty string
+	Players    []string
+	Channels   map[string]string
+	// Locales of the games of the players, which the race is announced in.
+	Locales  map[string]string
+	Out      map[string]bool
+	Resolved bool
+	mutex    sync.Mutex
+}
+
+// Gets the rating of a player, unrated players have the default rating.
+func ratingOf(dd DifficultyData) float64 {
+	if dd.RatedGames == 0 {
+		return DefaultRating
+	}
+
+	return dd.Rating
+}
+
+// Calculates the new rating of a player with the given score, 1 for a win, 0.5 for a draw and 0 for a loss.
+func newRating(rating, opponentRating, score float64, ratedGames int64) float64 {
+	expected := 1 / (1 + math.Pow(10, (opponentRating-rating)/400))
+
+	// Rating changes faster while a player is still provisional.
+	k := float64(20)
+	if ratedGames < 30 {
+		k = 40
+	}
+
+	return rating + k*(score-expected)
+}
+
+func formatRating(locale string, dd DifficultyData) string {
+	if dd.RatedGames == 0 {
+		return tr(locale, "rating.unrated")
+	}
+
+	return trPlural(locale, "rating.value", float64(dd.RatedGames), dd.Rating, formatNumber(dd.RatedGames))
+}
+
+// Stores the new rating of a player and counts the rated game.
+func setRating(userID, difficulty string, rating float64) {
+	filter := bson.D{{
+		Key:   "userID",
+		Value: userID,
+	}}
+
+	if _, err := d.Collection("userdata").UpdateOne(
+		context.TODO(),
+		filter,
+		bson.D{{
+			Key:   "$set",
+			Value: bson.D{{Key: fmt.Sprintf("difficulties.%s.rating", difficulty), Value: rating}},
+		}, {
+			Key:   "$inc",
+			Value: bson.D{{Key: fmt.Sprintf("difficulties.%s.ratedGames", difficulty), Value: 1}},
+		}},
+		options.Update().SetUpsert(true),
+	); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// The maximum rating difference a player accepts, growing the longer they wait.
+func ratingWindow(waited time.Duration) float64 {
+	return math.Min(100+50*math.Floor(waited.Seconds()/30), 600)
+}
+
+func matchmakingKey(guildID, difficulty string) string {
+	return fmt.Sprintf("%s:%s", guildID, difficulty)
+}
+
+// Adds a player to the queue and tries to find them an opponent. Players can only wait in one
+// queue at a time, so they can't be matched into two races at once.
+func joinMatchmaking(guildID, difficulty string, player *queuedPlayer) bool {
+	matchmakingMutex.Lock()
+	defer matchmakingMutex.Unlock()
+
+	for _, queue := range MatchmakingQueues {
+		for _, queued := range queue {
+			if queued.UserID == player.UserID {
+				return false
+			}
+		}
+	}
+
+	key := matchmakingKey(guildID, difficulty)
+	MatchmakingQueues[key] = append(MatchmakingQueues[key], player)
+	pairQueue(key)
+
+	return true
+}
+
+// Removes a player from every queue they are in.
+func leaveMatchmaking(userID string) (*queuedPlayer, bool) {
+	matchmakingMutex.Lock()
+	defer matchmakingMutex.Unlock()
+
+	return removeFromQueues(userID, "")
+}
+
+// Removes a player from every queue but the one of the skipped key, getting the first entry
+// removed. Must be called with the mutex held.
+func removeFromQueues(userID, skip string) (*queuedPlayer, bool) {
+	var removed *queuedPlayer
+	for key, queue := range MatchmakingQueues {
+		if key == skip {
+			continue
+		}
+
+		remaining := queue[:0]
+		for _, queued := range queue {
+			if queued.UserID != userID {
+				remaining = append(remaining, queued)
+			} else if removed == nil {
+				removed = queued
+			}
+		}
+		MatchmakingQueues[key] = remaining
+	}
+
+	return removed, removed != nil
+}
+
+// Pairs up the players in a queue whose ratings are close enough. Must be called with the mutex held.
+func pairQueue(key string) {
+	queue := MatchmakingQueues[key]
+	difficulty := key[strings.LastIndex(key, ":")+1:]
+
+	for a := 0; a < len(queue); a++ {
+		for b := a + 1; b < len(queue); b++ {
+			// The player who waited the longest decides how far apart the ratings may be.
+			window := ratingWindow(time.Since(queue[a].JoinedAt))
+			if math.Abs(queue[a].Rating-queue[b].Rating) > window {
+				continue
+			}
+
+			first, second := queue[a], queue[b]
+			queue = append(queue[:b], queue[b+1:]...)
+			queue = append(queue[:a], queue[a+1:]...)
+			// Players that still ended up in another queue are taken out of it too.
+			removeFromQueues(first.UserID, key)
+			removeFromQueues(second.UserID, key)
+			go startRace(difficulty, first, second)
+			a--
+			break
+		}
+	}
+
+	MatchmakingQueues[key] = queue
+}
+
+// Removes players that waited too long and pairs players whose rating window grew.
+func startMatchmaker() {
+	ticker := time.NewTicker(15 * time.Second)
+	matchmakingChannel = make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				var expired []*queuedPlayer
+
+				matchmakingMutex.Lock()
+				for key, queue := range MatchmakingQueues {
+					var remaining []*queuedPlayer
+					for _, queued := range queue {
+						if time.Since(queued.JoinedAt) >= matchmakingTimeout {
+							expired = append(expired, queued)
+							continue
+						}
+						remaining = append(remaining, queued)
+					}
+					MatchmakingQueues[key] = remaining
+					pairQueue(key)
+				}
+				matchmakingMutex.Unlock()
+
+				for _, queued := range expired {
+					content := tr(interactionLocale(queued.Interaction), "matchmake.expired")
+					components := []discordgo.MessageComponent{}
+					if _, err := s.InteractionResponseEdit(queued.Interaction.Interaction, &discordgo.WebhookEdit{
+						Content:    &content,
+						Components: &components,
+					}); err != nil {
+						fmt.Println(err)
+					}
+				}
+			case <-matchmakingChannel:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Starts a race between two matched players, both get a copy of the same board.
+func startRace(difficulty string, first, second *queuedPlayer) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+	race := &Race{
+		Difficulty: difficulty,
+		Players:    []string{first.UserID, second.UserID},
+		Channels:   make(map[string]string),
+		Locales:    make(map[string]string),
+		Out:        make(map[string]bool),
+	}
+
+	board := minesweeper.NewGame(difficultyFromString(difficulty), 0, false, false)
+	for _, player := range []*queuedPlayer{first, second} {
+		if _, ok := getGame(player.UserID); ok {
+			race.Out[player.UserID] = true
+			continue
+		}
+
+		StartGame(s, player.Interaction, board.Clone(), difficulty, player.UserID)
+		game, ok := getGame(player.UserID)
+		if !ok {
+			race.Out[player.UserID] = true
+			continue
+		}
+		game.Race = race
+		race.Channels[player.UserID] = game.ChannelID
+		race.Locales[player.UserID] = game.Locale
+	}
+
+	race.announce(func(locale string) string {
+		return tr(locale, "race.started", strings.ToUpper(difficulty), first.UserID, first.Rating, second.UserID, second.Rating)
+	})
+}
+
+// Sends a message to every channel the race is played in, in the language of the player whose
+// game is there.
+func (r *Race) announce(message func(locale string) string) {
+	sent := make(map[string]bool)
+	for userID, channelID := range r.Channels {
+		if sent[channelID] {
+			continue
+		}
+		sent[channelID] = true
+
+		if _, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+			Content:         message(r.Locales[userID]),
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		}, RequestOption); err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
+// Records the end of a player's game, resolving the race once there is a winner or nobody is left.
+func (r *Race) finish(userID string, event int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.Resolved {
+		return
+	}
+
+	r.Out[userID] = true
+
+	var opponentID string
+	for _, player := range r.Players {
+		if player != userID {
+			opponentID = player
+		}
+	}
+
+	score := float64(1)
+	if event != minesweeper.Won {
+		if !r.Out[opponentID] {
+			return
+		}
+		// Nobody cleared the board.
+		score = 0.5
+	}
+	r.Resolved = true
+
+	playerData := getUserData(userID).Difficulties[r.Difficulty]
+	opponentData := getUserData(opponentID).Difficulties[r.Difficulty]
+	playerRating := ratingOf(playerData)
+	opponentRating := ratingOf(opponentData)
+
+	newPlayerRating := newRating(playerRating, opponentRating, score, playerData.RatedGames)
+	newOpponentRating := newRating(opponentRating, playerRating, 1-score, opponentData.RatedGames)
+
+	setRating(userID, r.Difficulty, newPlayerRating)
+	setRating(opponentID, r.Difficulty, newOpponentRating)
+
+	r.announce(func(locale string) string {
+		content := tr(locale, "race.won", userID, opponentID)
+		if score != 1 {
+			content = tr(locale, "race.draw", userID, opponentID)
+		}
+
+		return content + "\n" + tr(locale, "race.ratings",
+			userID, playerRating, newPlayerRating,
+			opponentID, opponentRating, newOpponentRating)
+	})
+}
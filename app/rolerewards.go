@@ -0,0 +1,468 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// What earns the role of a reward.
+const (
+	RewardAchievement = "achievement"
+	RewardWins        = "wins"
+	RewardTopThree    = "top3"
+)
+
+// Places on the guild leaderboard that hold a top spot reward.
+const rewardTopSpots = 3
+
+var (
+	ErrRewardRoleManaged  = &localizedError{id: "reward.error.managed"}
+	ErrRewardNoPermission = &localizedError{id: "reward.error.noPermission"}
+	ErrRewardRoleTooHigh  = &localizedError{id: "reward.error.tooHigh"}
+)
+
+// Describes what earns the role of a reward, in English for the audit log of the guild.
+func (r RoleReward) String() string {
+	switch r.Type {
+	case RewardAchievement:
+		if achievement, ok := Achievements[r.Achievement]; ok {
+			return fmt.Sprintf("Unlock **%s**", achievement.Name)
+		}
+		return fmt.Sprintf("Unlock achievement %d", r.Achievement)
+	case RewardWins:
+		if r.Difficulty == "" {
+			return fmt.Sprintf("Win %d games", r.Wins)
+		}
+		return fmt.Sprintf("Win %d games on %s", r.Wins, strings.ToUpper(r.Difficulty))
+	case RewardTopThree:
+		return fmt.Sprintf("Hold a top %d time on %s", rewardTopSpots, strings.ToUpper(r.Difficulty))
+	}
+
+	return r.Type
+}
+
+// Describes what earns the role of a reward in the language of the locale.
+func (r RoleReward) describe(locale string) string {
+	switch r.Type {
+	case RewardAchievement:
+		if achievement, ok := Achievements[r.Achievement]; ok {
+			name, _ := achievement.localized(locale, r.Achievement)
+			return tr(locale, "reward.achievement", name)
+		}
+		return tr(locale, "reward.unknownAchievement", r.Achievement)
+	case RewardWins:
+		if r.Difficulty == "" {
+			return trPlural(locale, "reward.wins", float64(r.Wins), r.Wins)
+		}
+		return trPlural(locale, "reward.winsOn", float64(r.Wins), r.Wins, strings.ToUpper(r.Difficulty))
+	case RewardTopThree:
+		return tr(locale, "reward.topSpot", rewardTopSpots, strings.ToUpper(r.Difficulty))
+	}
+
+	return r.Type
+}
+
+// Checks whether the user data earns the role of a reward. Top spots are checked against the
+// leaderboard by syncLeaderboardRewards instead.
+func (r RoleReward) earnedBy(userData UserData) bool {
+	switch r.Type {
+	case RewardAchievement:
+		return userData.hasAchievement(r.Achievement)
+	case RewardWins:
+		if r.Difficulty != "" {
+			return userData.Difficulties[r.Difficulty].Wins >= r.Wins
+		}
+		var wins int64
+		for _, difficulty := range difficultyOrder {
+			wins += userData.Difficulties[difficulty].Wins
+		}
+		return wins >= r.Wins
+	}
+
+	return false
+}
+
+// Replaces the reward for a role, every role has at most one.
+func setRoleReward(guildID string, reward RoleReward) error {
+	if err := removeRoleReward(guildID, reward.RoleID); err != nil {
+		return err
+	}
+
+	_, err := d.Collection("guilddata").UpdateOne(
+		context.TODO(),
+		bson.D{{Key: "guildID", Value: guildID}},
+		bson.D{{Key: "$push", Value: bson.D{{Key: "config.roleRewards", Value: reward}}}},
+		options.Update().SetUpsert(true),
+	)
+
+	return err
+}
+
+func removeRoleReward(guildID, roleID string) error {
+	_, err := d.Collection("guilddata").UpdateOne(
+		context.TODO(),
+		bson.D{{Key: "guildID", Value: guildID}},
+		bson.D{{Key: "$pull", Value: bson.D{{Key: "config.roleRewards", Value: bson.D{{Key: "roleID", Value: roleID}}}}}},
+	)
+
+	return err
+}
+
+// Checks whether the bot can give out the role, which needs Manage Roles and the role to be
+// below the highest role of the bot.
+func canAssignRole(s *discordgo.Session, guildID, roleID string) error {
+	guild, err := s.State.Guild(guildID)
+	if err != nil {
+		return err
+	}
+	member, err := s.State.Member(guildID, s.State.User.ID)
+	if err != nil {
+		member, err = s.GuildMember(guildID, s.State.User.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	var target *discordgo.Role
+	var permissions int64
+	highest := 0
+	for _, role := range guild.Roles {
+		if role.ID == roleID {
+			target = role
+		}
+		if role.ID == guildID || isInArray(role.ID, member.Roles) {
+			permissions |= role.Permissions
+			if role.ID != guildID && role.Position > highest {
+				highest = role.Position
+			}
+		}
+	}
+
+	if target == nil {
+		return &localizedError{id: "reward.error.noRole", args: []interface{}{roleID}}
+	}
+	if target.Managed || target.ID == guildID {
+		return ErrRewardRoleManaged
+	}
+	if permissions&(discordgo.PermissionManageRoles|discordgo.PermissionAdministrator) == 0 {
+		return ErrRewardNoPermission
+	}
+	if target.Position >= highest {
+		return ErrRewardRoleTooHigh
+	}
+
+	return nil
+}
+
+func getMember(s *discordgo.Session, guildID, userID string) (*discordgo.Member, error) {
+	if member, err := s.State.Member(guildID, userID); err == nil {
+		return member, nil
+	}
+
+	return s.GuildMember(guildID, userID)
+}
+
+// Gives or takes the role of a reward, logging the change to the audit log of the guild
+// and the reward log.
+func setRewardRole(s *discordgo.Session, guildID string, member *discordgo.Member, reward RoleReward, grant bool) {
+	if isInArray(reward.RoleID, member.Roles) == grant {
+		return
+	}
+	if err := canAssignRole(s, guildID, reward.RoleID); err != nil {
+		fmt.Printf("Can't update reward role %s in %s: %v\n", reward.RoleID, guildID, err)
+		return
+	}
+
+	action := "granted"
+	reason := "Reward: " + reward.String()
+	var err error
+	if grant {
+		err = s.GuildMemberRoleAdd(guildID, member.User.ID, reward.RoleID, discordgo.WithAuditLogReason(reason))
+	} else {
+		action = "revoked"
+		err = s.GuildMemberRoleRemove(guildID, member.User.ID, reward.RoleID, discordgo.WithAuditLogReason(reason))
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if _, err := d.Collection("rolerewardlog").InsertOne(context.TODO(), RoleRewardLogEntry{
+		GuildID: guildID,
+		UserID:  member.User.ID,
+		RoleID:  reward.RoleID,
+		Action:  action,
+		Reason:  reward.String(),
+		Reward:  reward,
+		At:      time.Now(),
+	}); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// Gives the member of the guild the roles of the rewards their user data earns. Achievement
+// and win rewards are never taken away, the member may have gotten the role by hand.
+func syncMemberRewards(s *discordgo.Session, guildID string, userData UserData) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+	if guildID == "" {
+		return
+	}
+
+	rewards := getGuildConfig(guildID).RoleRewards
+	if len(rewards) == 0 {
+		return
+	}
+
+	member, err := getMember(s, guildID, userData.UserID)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, reward := range rewards {
+		if reward.Type == RewardTopThree || !reward.earnedBy(userData) {
+			continue
+		}
+		setRewardRole(s, guildID, member, reward, true)
+	}
+}
+
+// Moves the roles of the top spot rewards of the guild to the members currently holding the
+// top spots.
+func syncLeaderboardRewards(s *discordgo.Session, guildID string, rewards []RoleReward) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+
+	for _, reward := range rewards {
+		if reward.Type != RewardTopThree {
+			continue
+		}
+
+		entries, _, err := getLeaderboard(guildID, difficultyFromString(reward.Difficulty), "", PeriodAllTime, 0)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		var top []string
+		for index, entry := range entries {
+			if index >= rewardTopSpots {
+				break
+			}
+			top = append(top, entry.UserID)
+		}
+
+		for _, userID := range rewardRoleHolders(s, guildID, reward.RoleID) {
+			if isInArray(userID, top) {
+				continue
+			}
+			member, err := getMember(s, guildID, userID)
+			if err != nil {
+				continue
+			}
+			setRewardRole(s, guildID, member, reward, false)
+		}
+		for _, userID := range top {
+			member, err := getMember(s, guildID, userID)
+			if err != nil {
+				// Players that left the guild keep their spot, but have no role to get.
+				continue
+			}
+			setRewardRole(s, guildID, member, reward, true)
+		}
+	}
+}
+
+// Gets the members that may hold the role of a reward: the ones the bot gave it to according to
+// the reward log, and the cached members that have it. The cache alone misses members that
+// weren't seen since the bot started.
+func rewardRoleHolders(s *discordgo.Session, guildID, roleID string) []string {
+	var holders []string
+	if guild, err := s.State.Guild(guildID); err == nil {
+		s.State.RLock()
+		for _, member := range guild.Members {
+			if isInArray(roleID, member.Roles) {
+				holders = append(holders, member.User.ID)
+			}
+		}
+		s.State.RUnlock()
+	}
+
+	// The latest entry of every member tells whether the bot last gave or took the role.
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "guildID", Value: guildID}, {Key: "roleID", Value: roleID}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "at", Value: -1}}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$userID"},
+			{Key: "action", Value: bson.D{{Key: "$first", Value: "$action"}}},
+		}}},
+		bson.D{{Key: "$match", Value: bson.D{{Key: "action", Value: "granted"}}}},
+	}
+	cursor, err := d.Collection("rolerewardlog").Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		fmt.Println(err)
+		return holders
+	}
+
+	var granted []struct {
+		UserID string `bson:"_id"`
+	}
+	if err := cursor.All(context.TODO(), &granted); err != nil {
+		fmt.Println(err)
+		return holders
+	}
+	for _, entry := range granted {
+		if !isInArray(entry.UserID, holders) {
+			holders = append(holders, entry.UserID)
+		}
+	}
+
+	return holders
+}
+
+// Syncs the top spot rewards of every guild that has any.
+func syncAllLeaderboardRewards() {
+	cursor, err := d.Collection("guilddata").Find(
+		context.TODO(),
+		bson.D{{Key: "config.roleRewards.type", Value: RewardTopThree}},
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var guilds []GuildData
+	if err := cursor.All(context.TODO(), &guilds); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, guild := range guilds {
+		syncLeaderboardRewards(s, guild.GuildID, guild.Config.RoleRewards)
+	}
+}
+
+func getRoleRewardLog(guildID string, limit int64) ([]RoleRewardLogEntry, error) {
+	var entries []RoleRewardLogEntry
+	cursor, err := d.Collection("rolerewardlog").Find(
+		context.TODO(),
+		bson.D{{Key: "guildID", Value: guildID}},
+		options.Find().SetSort(bson.D{{Key: "at", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cursor.All(context.TODO(), &entries)
+	return entries, err
+}
+
+func describeRoleRewards(locale string, rewards []RoleReward) string {
+	if len(rewards) == 0 {
+		return tr(locale, "config.none")
+	}
+
+	lines := make([]string, 0, len(rewards))
+	for _, reward := range rewards {
+		lines = append(lines, fmt.Sprintf("<@&%s>: %s", reward.RoleID, reward.describe(locale)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func generateRewardLogEmbed(locale, guildID string) (*discordgo.MessageEmbed, error) {
+	entries, err := getRoleRewardLog(guildID, 20)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		// Entries logged before the reward was stored only have the English reason.
+		reason := entry.Reason
+		if entry.Reward.Type != "" {
+			reason = entry.Reward.describe(locale)
+		}
+		lines = append(lines, tr(locale, "reward.log."+entry.Action, entry.At.Unix(), entry.RoleID, entry.UserID, reason))
+	}
+	description := strings.Join(lines, "\n")
+	if description == "" {
+		description = tr(locale, "reward.log.empty")
+	}
+
+	return &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       tr(locale, "reward.log.title"),
+		Description: description,
+		Color:       randomEmbedColor(),
+	}, nil
+}
+
+// Handles the reward subcommands of /config.
+func configRewards(s *discordgo.Session, i *discordgo.InteractionCreate, subcommand *discordgo.ApplicationCommandInteractionDataOption) (*discordgo.MessageEmbed, error) {
+	optionMap := mapOptions(subcommand.Options)
+
+	switch subcommand.Name {
+	case "add":
+		role := optionMap["role"].RoleValue(s, i.GuildID)
+		if err := canAssignRole(s, i.GuildID, role.ID); err != nil {
+			return nil, err
+		}
+
+		reward := RoleReward{
+			RoleID: role.ID,
+			Type:   optionMap["type"].StringValue(),
+		}
+		if v, ok := optionMap["difficulty"]; ok {
+			reward.Difficulty = v.StringValue()
+		}
+		switch reward.Type {
+		case RewardAchievement:
+			v, ok := optionMap["achievement"]
+			if !ok {
+				return nil, &localizedError{id: "reward.error.pickAchievement"}
+			}
+			if _, ok := Achievements[int(v.IntValue())]; !ok {
+				return nil, &localizedError{id: "reward.error.noAchievement", args: []interface{}{v.IntValue()}}
+			}
+			reward.Achievement = int(v.IntValue())
+		case RewardWins:
+			v, ok := optionMap["wins"]
+			if !ok || v.IntValue() <= 0 {
+				return nil, &localizedError{id: "reward.error.pickWins"}
+			}
+			reward.Wins = v.IntValue()
+		case RewardTopThree:
+			if reward.Difficulty == "" {
+				return nil, &localizedError{id: "reward.error.pickDifficulty"}
+			}
+		}
+
+		if err := setRoleReward(i.GuildID, reward); err != nil {
+			return nil, err
+		}
+		if reward.Type == RewardTopThree {
+			go syncLeaderboardRewards(s, i.GuildID, []RoleReward{reward})
+		}
+	case "remove":
+		if err := removeRoleReward(i.GuildID, optionMap["role"].RoleValue(s, i.GuildID).ID); err != nil {
+			return nil, err
+		}
+	case "log":
+		return generateRewardLogEmbed(interactionLocale(i), i.GuildID)
+	}
+
+	return generateConfigEmbed(interactionLocale(i), i.GuildID), nil
+}
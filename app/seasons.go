@@ -0,0 +1,399 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/minesweeper"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Leaderboard periods selectable by users.
+const (
+	PeriodAllTime = "alltime"
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+	PeriodSeason  = "season"
+)
+
+var (
+	ErrNoSeason      = &localizedError{id: "error.noSeason"}
+	ErrUnknownSeason = &localizedError{id: "error.unknownSeason"}
+)
+
+// Longest name a season can have. Season names end up in the custom IDs of the leaderboard page
+// buttons next to the guild ID, metric and preset key, which Discord limits to 100 characters.
+const maxSeasonName = 16
+
+// Amount of players per difficulty that are mentioned in the season end announcement.
+var seasonAnnouncementSize = 3
+
+// Gets the key that leaderboard entries of the given period and time are stored under.
+func periodKey(period string, t time.Time) (string, error) {
+	t = t.UTC()
+	switch period {
+	case PeriodWeekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("week-%d-%02d", year, week), nil
+	case PeriodMonthly:
+		return fmt.Sprintf("month-%d-%02d", t.Year(), t.Month()), nil
+	case PeriodSeason:
+		season, ok := getSeasonAt(t)
+		if !ok {
+			return "", ErrNoSeason
+		}
+		return seasonPeriodKey(season.Name), nil
+	}
+
+	return PeriodAllTime, nil
+}
+
+func seasonPeriodKey(name string) string {
+	return fmt.Sprintf("season-%s", name)
+}
+
+// Gets the season a period key belongs to, if it is the key of a season that has been archived.
+func archivedSeason(key string) (Season, bool) {
+	if !strings.HasPrefix(key, "season-") {
+		return Season{}, false
+	}
+
+	season, ok := getSeason(strings.TrimPrefix(key, "season-"))
+	return season, ok && season.Archived
+}
+
+// Gets all the period keys a result achieved at the given time counts towards.
+func currentPeriodKeys(t time.Time) []string {
+	var keys []string
+	for _, period := range []string{PeriodAllTime, PeriodWeekly, PeriodMonthly, PeriodSeason} {
+		key, err := periodKey(period, t)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Gets a human readable description of a period key.
+func periodLabel(locale, key string) string {
+	switch {
+	case strings.HasPrefix(key, "week-"):
+		var year, week int
+		fmt.Sscanf(key, "week-%d-%d", &year, &week)
+		return tr(locale, "period.week", week, year)
+	case strings.HasPrefix(key, "month-"):
+		var year, month int
+		fmt.Sscanf(key, "month-%d-%d", &year, &month)
+		return tr(locale, "period.month", tr(locale, fmt.Sprintf("month.%d", month)), year)
+	case strings.HasPrefix(key, "season-"):
+		name := strings.TrimPrefix(key, "season-")
+		season, ok := getSeason(name)
+		if !ok {
+			return tr(locale, "period.season", name)
+		}
+		if season.End.Before(time.Now()) {
+			return tr(locale, "period.seasonEnded", name, season.End.Unix())
+		}
+		return tr(locale, "period.seasonEnds", name, season.End.Unix())
+	}
+
+	return tr(locale, "period.allTime")
+}
+
+func getSeason(name string) (Season, bool) {
+	var season Season
+	filter := bson.D{{
+		Key:   "name",
+		Value: name,
+	}}
+	if err := d.Collection("seasons").FindOne(context.TODO(), filter).Decode(&season); err != nil {
+		return season, false
+	}
+
+	return season, true
+}
+
+// Gets the season running at the given time.
+func getSeasonAt(t time.Time) (Season, bool) {
+	var season Season
+	filter := bson.D{{
+		Key:   "start",
+		Value: bson.D{{Key: "$lte", Value: t}},
+	}, {
+		Key:   "end",
+		Value: bson.D{{Key: "$gt", Value: t}},
+	}}
+	if err := d.Collection("seasons").FindOne(context.TODO(), filter).Decode(&season); err != nil {
+		return season, false
+	}
+
+	return season, true
+}
+
+// Creates or updates a season, refusing seasons that overlap with another one.
+func setSeason(name string, start, end time.Time) error {
+	if utf8.RuneCountInString(name) > maxSeasonName {
+		return fmt.Errorf("season names can be at most %d characters long", maxSeasonName)
+	}
+	if !end.After(start) {
+		return errors.New("a season has to end after it starts")
+	}
+
+	overlapFilter := bson.D{{
+		Key:   "name",
+		Value: bson.D{{Key: "$ne", Value: name}},
+	}, {
+		Key:   "start",
+		Value: bson.D{{Key: "$lt", Value: end}},
+	}, {
+		Key:   "end",
+		Value: bson.D{{Key: "$gt", Value: start}},
+	}}
+	var overlapping Season
+	if err := d.Collection("seasons").FindOne(context.TODO(), overlapFilter).Decode(&overlapping); err == nil {
+		return fmt.Errorf("overlaps with season %s", overlapping.Name)
+	}
+
+	filter := bson.D{{
+		Key:   "name",
+		Value: name,
+	}}
+	_, err := d.Collection("seasons").UpdateOne(
+		context.TODO(),
+		filter,
+		bson.D{{
+			Key: "$set",
+			Value: bson.D{
+				{Key: "name", Value: name},
+				{Key: "start", Value: start},
+				{Key: "end", Value: end},
+				{Key: "archived", Value: false},
+			},
+		}},
+		options.Update().SetUpsert(true),
+	)
+
+	return err
+}
+
+// Archives the final standings of every season that has ended and announces the winners.
+func closeEndedSeasons() {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+	filter := bson.D{{
+		Key:   "end",
+		Value: bson.D{{Key: "$lte", Value: time.Now()}},
+	}, {
+		Key:   "archived",
+		Value: false,
+	}}
+
+	cursor, err := d.Collection("seasons").Find(context.TODO(), filter)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var seasons []Season
+	if err := cursor.All(context.TODO(), &seasons); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, season := range seasons {
+		if err := archiveSeason(season); err != nil {
+			fmt.Printf("Failed to archive season %s\n%v\n", season.Name, err)
+			continue
+		}
+
+		if _, err := d.Collection("seasons").UpdateOne(
+			context.TODO(),
+			bson.D{{Key: "name", Value: season.Name}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "archived", Value: true}}}},
+		); err != nil {
+			fmt.Println(err)
+			continue
+		}
+
+		announceSeasonEnd(season)
+		fmt.Printf("Closed season %s\n", season.Name)
+	}
+}
+
+func archiveSeason(season Season) error {
+	key := seasonPeriodKey(season.Name)
+	cursor, err := d.Collection("leaderboardentries").Find(
+		context.TODO(),
+		bson.D{{Key: "period", Value: key}},
+		options.Find().SetSort(bson.D{
+			{Key: "guildID", Value: 1},
+			{Key: "difficulty", Value: 1},
+			{Key: "preset", Value: 1},
+			{Key: "time", Value: 1},
+			{Key: "userId", Value: 1},
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	var entries []LeaderboardEntry
+	if err := cursor.All(context.TODO(), &entries); err != nil {
+		return err
+	}
+
+	// Rerunning an archive replaces the previous attempt.
+	if _, err := d.Collection("seasonarchives").DeleteMany(context.TODO(), bson.D{{Key: "season", Value: season.Name}}); err != nil {
+		return err
+	}
+
+	var standings []interface{}
+	var rank int64
+	var lastBoard string
+	for _, entry := range entries {
+		board := fmt.Sprintf("%s-%d-%s", entry.GuildID, entry.Difficulty, entry.Preset)
+		if board != lastBoard {
+			rank = 0
+			lastBoard = board
+		}
+		rank++
+
+		standings = append(standings, SeasonStanding{
+			Season:     season.Name,
+			GuildID:    entry.GuildID,
+			Difficulty: entry.Difficulty,
+			Preset:     entry.Preset,
+			Rank:       rank,
+			UserID:     entry.UserID,
+			Time:       entry.Time,
+		})
+	}
+
+	if len(standings) == 0 {
+		return nil
+	}
+
+	_, err = d.Collection("seasonarchives").InsertMany(context.TODO(), standings)
+	return err
+}
+
+// Gets a single page of the final standings of an archived season along with the total amount of
+// standings, in the same shape as the entries of a live leaderboard.
+func getArchivedLeaderboard(seasonName, guildID string, difficulty int, preset string, page int64) ([]LeaderboardEntry, int64, error) {
+	var leaderboard []LeaderboardEntry
+	filter := bson.D{
+		{Key: "season", Value: seasonName},
+		{Key: "guildID", Value: guildID},
+		{Key: "difficulty", Value: difficulty},
+		{Key: "preset", Value: preset},
+	}
+	if guildID == "global" {
+		if hidden := getHiddenUsers(); len(hidden) > 0 {
+			filter = append(filter, bson.E{Key: "userId", Value: bson.D{{Key: "$nin", Value: hidden}}})
+		}
+	}
+
+	total, err := d.Collection("seasonarchives").CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return leaderboard, 0, err
+	}
+
+	cursor, err := d.Collection("seasonarchives").Find(
+		context.TODO(),
+		filter,
+		options.Find().
+			SetSort(bson.D{{Key: "rank", Value: 1}}).
+			SetSkip(page*leaderboardPageSize).
+			SetLimit(leaderboardPageSize),
+	)
+	if err != nil {
+		return leaderboard, total, err
+	}
+
+	err = cursor.All(context.TODO(), &leaderboard)
+	return leaderboard, total, err
+}
+
+func getSeasonStandings(seasonName, guildID string, difficulty int, limit int64) []SeasonStanding {
+	var standings []SeasonStanding
+	cursor, err := d.Collection("seasonarchives").Find(
+		context.TODO(),
+		bson.D{
+			{Key: "season", Value: seasonName},
+			{Key: "guildID", Value: guildID},
+			{Key: "difficulty", Value: difficulty},
+			{Key: "preset", Value: ""},
+		},
+		options.Find().SetSort(bson.D{{Key: "rank", Value: 1}}).SetLimit(limit),
+	)
+	if err != nil {
+		fmt.Println(err)
+		return standings
+	}
+
+	if err := cursor.All(context.TODO(), &standings); err != nil {
+		fmt.Println(err)
+	}
+
+	return standings
+}
+
+// Announces the end of a season in every channel that has a leaderboard message configured.
+func announceSeasonEnd(season Season) {
+	announced := make(map[string]bool)
+	for _, message := range getLeaderboardMessages() {
+		if announced[message.ChannelID] {
+			continue
+		}
+		announced[message.ChannelID] = true
+
+		locale := Messages.Fallback
+		if guild, err := s.State.Guild(message.GuildID); err == nil {
+			locale = string(guild.PreferredLocale)
+		}
+
+		embed := &discordgo.MessageEmbed{
+			Type:        discordgo.EmbedTypeRich,
+			Title:       tr(locale, "season.ended", season.Name),
+			Description: tr(locale, "season.finalStandings"),
+			Color:       randomEmbedColor(),
+			Timestamp:   season.End.Format(time.RFC3339),
+		}
+
+		for _, difficulty := range []int{minesweeper.Easy, minesweeper.Medium, minesweeper.Hard} {
+			standings := getSeasonStandings(season.Name, message.GuildID, difficulty, int64(seasonAnnouncementSize))
+
+			value := tr(locale, "season.nobodyPlayed")
+			if len(standings) > 0 {
+				value = ""
+			}
+			for _, standing := range standings {
+				value += fmt.Sprintf("**#%d** <@!%s> - %.3fs\n", standing.Rank, standing.UserID, standing.Time)
+			}
+
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:   strings.ToUpper(difficultyToString(difficulty)),
+				Value:  value,
+				Inline: true,
+			})
+		}
+
+		if _, err := s.ChannelMessageSendComplex(message.ChannelID, &discordgo.MessageSend{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		}, RequestOption); err != nil {
+			fmt.Println(err)
+		}
+	}
+}
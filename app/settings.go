@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Toggleable settings, mapping the custom ID of their button to the setting they flip.
+var settingToggles = []struct {
+	CustomID string
+	Key      string
+	// Message ID of the label.
+	Label string
+	// Whether the setting is shown as ON while the stored value is false.
+	Inverted bool
+	Value    func(UserSettings) bool
+}{
+	{
+		CustomID: "settingsflagmode",
+		Key:      "flagModeOn",
+		Label:    "settings.flagMode",
+		Value:    func(settings UserSettings) bool { return settings.FlagModeOn },
+	},
+	{
+		CustomID: "settingsping",
+		Key:      "noPing",
+		Label:    "settings.ping",
+		Inverted: true,
+		Value:    func(settings UserSettings) bool { return settings.NoPing },
+	},
+	{
+		CustomID: "settingsephemeral",
+		Key:      "ephemeralResults",
+		Label:    "settings.ephemeral",
+		Value:    func(settings UserSettings) bool { return settings.EphemeralResults },
+	},
+	{
+		CustomID: "settingshideglobal",
+		Key:      "hideFromGlobal",
+		Label:    "settings.hideGlobal",
+		Value:    func(settings UserSettings) bool { return settings.HideFromGlobal },
+	},
+	{
+		CustomID: "settingsstreakshield",
+		Key:      "noStreakShield",
+		Label:    "settings.streakShield",
+		Inverted: true,
+		Value:    func(settings UserSettings) bool { return settings.NoStreakShield },
+	},
+	{
+		CustomID: "settingsbeatendm",
+		Key:      "beatenDMs",
+		Label:    "settings.beatenDM",
+		Value:    func(settings UserSettings) bool { return settings.BeatenDMs },
+	},
+	{
+		CustomID: "settingsspectators",
+		Key:      "noSpectators",
+		Label:    "settings.spectators",
+		Inverted: true,
+		Value:    func(settings UserSettings) bool { return settings.NoSpectators },
+	},
+}
+
+func getUserSettings(userID string) UserSettings {
+	return getUserData(userID).Settings
+}
+
+func setUserSetting(userID, key string, value interface{}) error {
+	_, err := d.Collection("userdata").UpdateOne(
+		context.TODO(),
+		bson.D{{Key: "userID", Value: userID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "settings." + key, Value: value}}}},
+		options.Update().SetUpsert(true),
+	)
+
+	return err
+}
+
+// Gets the users that do not want to appear on global leaderboards.
+func getHiddenUsers() []string {
+	cursor, err := d.Collection("userdata").Find(
+		context.TODO(),
+		bson.D{{Key: "settings.hideFromGlobal", Value: true}},
+		options.Find().SetProjection(bson.D{{Key: "userID", Value: 1}}),
+	)
+	if err != nil {
+		fmt.Println(err)
+		return nil
+	}
+
+	var users []UserData
+	if err := cursor.All(context.TODO(), &users); err != nil {
+		fmt.Println(err)
+		return nil
+	}
+
+	hidden := make([]string, 0, len(users))
+	for _, user := range users {
+		hidden = append(hidden, user.UserID)
+	}
+
+	return hidden
+}
+
+func localizedOnOff(locale string, on bool) string {
+	if on {
+		return tr(locale, "common.on")
+	}
+
+	return tr(locale, "common.off")
+}
+
+// Builds the settings message of a user, with select menus and a toggle button for every setting.
+func generateSettingsMessage(locale string, userData UserData) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	settings := userData.Settings
+	difficulty := settings.DefaultDifficulty
+	if difficulty == "" {
+		difficulty = "easy"
+	}
+	theme := settings.Theme
+	if _, ok := Themes[theme]; !ok || !canUseTheme(userData, theme) {
+		theme = DefaultTheme
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       tr(locale, "settings.title"),
+		Description: tr(locale, "settings.description", strings.ToUpper(difficulty), Themes[theme].Name),
+		Color:       randomEmbedColor(),
+	}
+
+	var difficultyOptions []discordgo.SelectMenuOption
+	for _, level := range difficultyOrder {
+		difficultyOptions = append(difficultyOptions, discordgo.SelectMenuOption{
+			Label:   strings.ToUpper(level[:1]) + level[1:],
+			Value:   level,
+			Default: level == difficulty,
+		})
+	}
+
+	themeNames := make([]string, 0, len(Themes))
+	for name := range Themes {
+		if canUseTheme(userData, name) {
+			themeNames = append(themeNames, name)
+		}
+	}
+	sort.Strings(themeNames)
+
+	var themeOptions []discordgo.SelectMenuOption
+	for _, name := range themeNames {
+		// Discord allows at most 25 options.
+		if len(themeOptions) >= 25 {
+			break
+		}
+		themeOptions = append(themeOptions, discordgo.SelectMenuOption{
+			Label:       Themes[name].Name,
+			Value:       name,
+			Description: Themes[name].Description,
+			Default:     name == theme,
+		})
+	}
+
+	var buttons []discordgo.MessageComponent
+	for _, toggle := range settingToggles {
+		on := toggle.Value(settings) != toggle.Inverted
+		button := &discordgo.Button{
+			CustomID: toggle.CustomID,
+			Label:    tr(locale, "settings.toggle", tr(locale, toggle.Label), localizedOnOff(locale, on)),
+			Style:    discordgo.SecondaryButton,
+		}
+		if on {
+			button.Style = discordgo.SuccessButton
+		}
+		buttons = append(buttons, button)
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    "settingsdifficulty",
+				Placeholder: tr(locale, "settings.difficulty"),
+				Options:     difficultyOptions,
+			},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    "settingstheme",
+				Placeholder: tr(locale, "settings.theme"),
+				Options:     themeOptions,
+			},
+		}},
+		discordgo.ActionsRow{Components: buttons[:3]},
+		discordgo.ActionsRow{Components: buttons[3:]},
+	}
+
+	return embed, components
+}
+
+// Responds to a settings component by updating the settings message.
+func respondSettings(s *discordgo.Session, i *discordgo.InteractionCreate, userID string) {
+	embed, components := generateSettingsMessage(interactionLocale(i), getUserData(userID))
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	}); err != nil {
+		cmdError(s, i, err)
+	}
+}
+
+func SettingsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+	userID, _ := getUserID(i)
+
+	embed, components := generateSettingsMessage(interactionLocale(i), getUserData(userID))
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:      1 << 6,
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	}); err != nil {
+		cmdError(s, i, err)
+	}
+}
+
+// Handles the select menus of the settings message.
+func settingsSelectHandler(key string) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		defer func() {
+			if err := recover(); err != nil {
+				handlePanic(err)
+			}
+		}()
+		userID, _ := getUserID(i)
+
+		values := i.MessageComponentData().Values
+		if len(values) == 0 {
+			return
+		}
+
+		if key == "theme" && !canUseTheme(getUserData(userID), values[0]) {
+			respondSettings(s, i, userID)
+			return
+		}
+
+		if err := setUserSetting(userID, key, values[0]); err != nil {
+			cmdError(s, i, err)
+			return
+		}
+
+		respondSettings(s, i, userID)
+	}
+}
+
+// Handles a toggle button of the settings message.
+func settingsToggleHandler(customID string) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		defer func() {
+			if err := recover(); err != nil {
+				handlePanic(err)
+			}
+		}()
+		userID, _ := getUserID(i)
+
+		for _, toggle := range settingToggles {
+			if toggle.CustomID != customID {
+				continue
+			}
+			if err := setUserSetting(userID, toggle.Key, !toggle.Value(getUserSettings(userID))); err != nil {
+				cmdError(s, i, err)
+				return
+			}
+		}
+
+		respondSettings(s, i, userID)
+	}
+}
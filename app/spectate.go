@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// How often the boards of spectators are checked for changes.
+const spectateRefresh = 3 * time.Second
+
+// Interaction tokens expire after 15 minutes, so spectators stop getting updates a little before.
+const spectateFor = 14 * time.Minute
+
+// Builds the board shown to spectators, which shows what the player sees with every button disabled.
+// Also gets what has been done in the game so far, to only refresh boards that changed. Both are
+// read under the lock of the game, so a click can't change them halfway.
+func spectatorBoard(game *MinesweeperGame, ended bool) ([]discordgo.MessageComponent, string) {
+	game.mutex.Lock()
+	rows := GenerateBoard(game, false, ended)
+	state := fmt.Sprintf("%d:%d:%d", game.Clicks, game.FlagClicks, game.Chords)
+	game.mutex.Unlock()
+
+	for _, row := range rows {
+		for _, component := range row.(discordgo.ActionsRow).Components {
+			button := component.(*discordgo.Button)
+			button.Disabled = true
+			// The custom IDs of the board would be handled as clicks if they were enabled.
+			button.CustomID = strings.Replace(button.CustomID, "board", "spectate", 1)
+		}
+	}
+
+	return rows, state
+}
+
+// Sends the spectator an ephemeral copy of the board of the game and keeps it up to date.
+func spectate(s *discordgo.Session, i *discordgo.InteractionCreate, game *MinesweeperGame) {
+	locale := interactionLocale(i)
+	if getUserSettings(game.UserID).NoSpectators {
+		respondEphemeral(s, i, tr(locale, "spectate.disabled", game.UserID))
+		return
+	}
+
+	board, state := spectatorBoard(game, false)
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:           1 << 6,
+			Content:         tr(locale, "spectate.watching", game.UserID, strings.ToUpper(game.Difficulty)),
+			Components:      board,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	}); err != nil {
+		cmdError(s, i, err)
+		return
+	}
+
+	go watchGame(s, i.Interaction, locale, game, state)
+}
+
+// Refreshes the board of a spectator until the game ends or the interaction expires.
+func watchGame(s *discordgo.Session, interaction *discordgo.Interaction, locale string, game *MinesweeperGame, last string) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+
+	ticker := time.NewTicker(spectateRefresh)
+	defer ticker.Stop()
+	expired := time.After(spectateFor)
+
+	edit := func(content string, board []discordgo.MessageComponent) {
+		if _, err := s.InteractionResponseEdit(interaction, &discordgo.WebhookEdit{
+			Content:    &content,
+			Components: &board,
+		}); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	for {
+		select {
+		case <-game.Removed:
+			// The board is final once HandleGameEnd removed the game.
+			board, _ := spectatorBoard(game, true)
+			edit(tr(locale, "spectate.ended", game.UserID), board)
+			return
+		case <-expired:
+			board, _ := spectatorBoard(game, false)
+			edit(tr(locale, "spectate.expired", game.UserID), board)
+			return
+		case <-ticker.C:
+			if board, state := spectatorBoard(game, false); state != last {
+				last = state
+				edit(tr(locale, "spectate.watching", game.UserID, strings.ToUpper(game.Difficulty)), board)
+			}
+		}
+	}
+}
+
+func SpectateCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+	target := mapOptions(i.ApplicationCommandData().Options)["user"].UserValue(s)
+
+	game, ok := getGame(target.ID)
+	if !ok {
+		respondEphemeral(s, i, tr(interactionLocale(i), "spectate.noGame", target.ID))
+		return
+	}
+
+	spectate(s, i, game)
+}
+
+// Handles the spectate button below the board of a game.
+func SpectateButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+
+	if game, ok := gameByFlagID(i.Message.ID); ok {
+		spectate(s, i, game)
+		return
+	}
+
+	respondEphemeral(s, i, tr(interactionLocale(i), "spectate.over"))
+}
+
+func respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:           1 << 6,
+			Content:         content,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	}); err != nil {
+		cmdError(s, i, err)
+	}
+}
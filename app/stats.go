@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Amount of games listed in the recent games of the history view.
+var recentGamesShown = 10
+
+// Most games a time chart can cover.
+var maxChartGames int64 = 500
+
+var outcomeEmojis = map[string]string{
+	OutcomeWon:      "🏆",
+	OutcomeLost:     "💥",
+	OutcomeGaveUp:   "🏳️",
+	OutcomeTimedOut: "⌛",
+}
+
+func StatsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	defer func() {
+		if err := recover(); err != nil {
+			handlePanic(err)
+		}
+	}()
+	subcommand := i.ApplicationCommandData().Options[0]
+	optionMap := mapOptions(subcommand.Options)
+	userID, _ := getUserID(i)
+	locale := interactionLocale(i)
+
+	targetID := userID
+	if target, ok := optionMap["target"]; ok {
+		targetID = target.UserValue(s).ID
+	}
+
+	var difficulty string
+	if v, ok := optionMap["difficulty"]; ok {
+		difficulty = v.StringValue()
+	}
+
+	switch subcommand.Name {
+	case "history":
+		// Respond with a deferred message update initially.
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		})
+
+		embed := generateHistoryEmbed(locale, targetID, difficulty)
+		if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Embeds: &[]*discordgo.MessageEmbed{embed},
+		}); err != nil {
+			cmdError(s, i, err)
+		}
+
+	case "chart":
+		// Respond with a deferred message update initially, rendering can take a moment.
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		})
+
+		chartType := ChartTime
+		if v, ok := optionMap["type"]; ok {
+			chartType = v.StringValue()
+		}
+
+		var games int64 = 50
+		if v, ok := optionMap["games"]; ok {
+			games = v.IntValue()
+		}
+		if games < 2 {
+			games = 2
+		}
+		if games > maxChartGames {
+			games = maxChartGames
+		}
+
+		userString := targetID
+		if user, err := getUser(targetID, false); err == nil {
+			userString = user.Username
+		}
+
+		var (
+			chart *bytes.Buffer
+			err   error
+		)
+		switch chartType {
+		case ChartOutcomes:
+			chart, err = renderOutcomeChart(
+				locale,
+				tr(locale, "chart.outcomesTitle", userString),
+				[]string{"easy", "medium", "hard", "custom"},
+				countOutcomes(targetID),
+			)
+		default:
+			title := tr(locale, "chart.timesTitle", userString, games)
+			if difficulty != "" {
+				title = tr(locale, "chart.timesTitleOn", userString, strings.ToUpper(difficulty), games)
+			}
+			chart, err = renderTimeChart(locale, title, getGameHistory(targetID, difficulty, games))
+		}
+		if err != nil {
+			cmdError(s, i, err)
+			return
+		}
+
+		if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Files: []*discordgo.File{
+				{
+					Name:        "chart.png",
+					ContentType: "attachment",
+					Reader:      chart,
+				},
+			},
+		}); err != nil {
+			cmdError(s, i, err)
+		}
+
+	case "export":
+		// Respond with a deferred ephemeral message initially, exports are only for the user themselves.
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Flags: 1 << 6,
+			},
+		})
+
+		format := "csv"
+		if v, ok := optionMap["format"]; ok {
+			format = v.StringValue()
+		}
+
+		records := getGameHistory(userID, difficulty, 0)
+		data, err := exportGames(records, format)
+		if err != nil {
+			cmdError(s, i, err)
+			return
+		}
+
+		content := trPlural(locale, "stats.export", float64(len(records)), formatNumber(int64(len(records))))
+		if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+			Files: []*discordgo.File{
+				{
+					Name:        fmt.Sprintf("minesweeper-games.%s", format),
+					ContentType: "attachment",
+					Reader:      bytes.NewReader(data),
+				},
+			},
+		}); err != nil {
+			cmdError(s, i, err)
+		}
+	}
+}
+
+func formatSeconds(locale string, seconds float64) string {
+	duration, err := time.ParseDuration(fmt.Sprintf("%fs", seconds))
+	if err != nil {
+		return fmt.Sprintf("%.3fs", seconds)
+	}
+
+	return humanizeDuration(locale, duration, 3)
+}
+
+func generateHistoryEmbed(locale, targetID, difficulty string) *discordgo.MessageEmbed {
+	userString := targetID
+	userImage := "https://cdn.discordapp.com/embed/avatars/0.png"
+	if user, err := getUser(targetID, false); err == nil {
+		userString = user.Username
+		userImage = user.AvatarURL("")
+	}
+
+	description := tr(locale, "stats.allDifficulties")
+	if difficulty != "" {
+		description = tr(locale, "stats.mode", strings.ToUpper(difficulty))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       tr(locale, "stats.title", userString),
+		Description: description,
+		Color:       randomEmbedColor(),
+		Thumbnail: &discordgo.MessageEmbedThumbnail{
+			URL: userImage,
+		},
+	}
+
+	records := getGameHistory(targetID, difficulty, 100)
+	if len(records) == 0 {
+		embed.Description += "\n" + tr(locale, "stats.noGames")
+		return embed
+	}
+
+	var recent string
+	for index, record := range records {
+		if index >= recentGamesShown {
+			break
+		}
+		timeString := tr(locale, "stats.neverStarted")
+		if record.Duration > 0 {
+			timeString = fmt.Sprintf("%.3fs", record.Duration)
+		}
+		recent += trPlural(locale, "stats.recentGame", float64(record.Clicks),
+			outcomeEmojis[record.Outcome],
+			strings.ToUpper(record.Difficulty),
+			timeString,
+			record.Clicks,
+			record.EndedAt.Unix()) + "\n"
+	}
+
+	var averages string
+	for _, size := range []int{10, 100} {
+		window := records
+		if len(window) > size {
+			window = window[:size]
+		}
+		average := tr(locale, "stats.noWins")
+		if seconds, ok := averageWinTime(window); ok {
+			average = formatSeconds(locale, seconds)
+		}
+		averages += tr(locale, "stats.average", size, average, winRate(window)*100) + "\n"
+	}
+
+	// Win rate of every block of 10 games, oldest first.
+	var trend []string
+	for _, chunk := range chunkRecords(records, 10) {
+		trend = append(trend, fmt.Sprintf("%.0f%%", winRate(chunk)*100))
+	}
+
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:  tr(locale, "stats.recentGames"),
+			Value: recent,
+		},
+		{
+			Name:  tr(locale, "stats.averages"),
+			Value: averages,
+		},
+		{
+			Name:  tr(locale, "stats.trend"),
+			Value: strings.Join(trend, " → "),
+		},
+	}
+	totalGames := countGames(targetID, difficulty)
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: trPlural(locale, "stats.total", float64(totalGames), formatNumber(totalGames)),
+	}
+
+	return embed
+}
+
+// Exports game records in the given format, either csv or json.
+func exportGames(records []GameRecord, format string) ([]byte, error) {
+	if format == "json" {
+		return json.MarshalIndent(records, "", "  ")
+	}
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	writer.Write([]string{"startedAt", "endedAt", "outcome", "difficulty", "preset", "guildID", "duration", "clicks", "flagClicks", "chords", "race"})
+	for _, record := range records {
+		startedAt := ""
+		if !record.StartedAt.IsZero() {
+			startedAt = record.StartedAt.Format(time.RFC3339)
+		}
+		writer.Write([]string{
+			startedAt,
+			record.EndedAt.Format(time.RFC3339),
+			record.Outcome,
+			record.Difficulty,
+			record.Preset,
+			record.GuildID,
+			strconv.FormatFloat(record.Duration, 'f', 3, 64),
+			strconv.FormatInt(record.Clicks, 10),
+			strconv.FormatInt(record.FlagClicks, 10),
+			strconv.FormatInt(record.Chords, 10),
+			strconv.FormatBool(record.Race),
+		})
+	}
+	writer.Flush()
+
+	return buffer.Bytes(), writer.Error()
+}
@@ -0,0 +1,73 @@
+package main
+
+import "sort"
+
+const (
+	// Broken streaks kept per difficulty to rank new ones against.
+	maxStreakHistory = 50
+	// Win streak lengths that earn a streak shield, every multiple of it does.
+	streakShieldEvery int64 = 10
+	// Most streak shields a user can hold at once.
+	maxStreakShields int64 = 3
+)
+
+// Records a broken streak in the history of the difficulty, getting its rank among the
+// recorded streaks. Equal streaks share a rank, and 0 means it is too short to be kept.
+func (dd *DifficultyData) recordStreak(streak int64) int {
+	// Streaks broken before the history existed only left their best behind.
+	if len(dd.StreakHistory) == 0 && dd.BestStreak > streak {
+		dd.StreakHistory = []int64{dd.BestStreak}
+	}
+
+	index := sort.Search(len(dd.StreakHistory), func(i int) bool {
+		return dd.StreakHistory[i] <= streak
+	})
+	if index >= maxStreakHistory {
+		return 0
+	}
+
+	dd.StreakHistory = append(dd.StreakHistory, 0)
+	copy(dd.StreakHistory[index+1:], dd.StreakHistory[index:])
+	dd.StreakHistory[index] = streak
+	if len(dd.StreakHistory) > maxStreakHistory {
+		dd.StreakHistory = dd.StreakHistory[:maxStreakHistory]
+	}
+
+	return index + 1
+}
+
+// Ends the win streak after a game that wasn't won, unless the user has a streak shield to
+// absorb it. Gets the lines telling the player.
+func breakStreak(locale string, userData *UserData, dd *DifficultyData) string {
+	if dd.WinStreak <= 0 {
+		return ""
+	}
+
+	if userData.StreakShields > 0 && !userData.Settings.NoStreakShield {
+		userData.StreakShields--
+		return "\n" + tr(locale, "game.shieldUsed", dd.WinStreak, userData.StreakShields) + "\n"
+	}
+
+	streak := dd.WinStreak
+	dd.WinStreak = 0
+	content := "\n" + tr(locale, "game.lostStreak", streak)
+	switch rank := dd.recordStreak(streak); {
+	case rank == 1:
+		content += "\n" + tr(locale, "game.streakBest")
+	case rank > 1:
+		content += "\n" + tr(locale, "game.streakRank", rank)
+	}
+
+	return content + "\n"
+}
+
+// Gives the user a streak shield when the win streak reaches a multiple of streakShieldEvery.
+// Gets the line telling the player.
+func earnStreakShield(locale string, userData *UserData, dd DifficultyData) string {
+	if dd.WinStreak%streakShieldEvery != 0 || userData.StreakShields >= maxStreakShields {
+		return ""
+	}
+
+	userData.StreakShields++
+	return "\n" + tr(locale, "game.shieldEarned", dd.WinStreak, userData.StreakShields, maxStreakShields)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"main/boardimage"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Themes shipped with the bot, THEMES_FILE can add to or replace them.
+//
+//go:embed themes.json
+var defaultThemes []byte
+
+type ThemeCell struct {
+	Emoji discordgo.ComponentEmoji `json:"emoji"`
+	Label string                   `json:"label"`
+	Style string                   `json:"style"`
+}
+
+type Theme struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Name of the boardimage theme final boards are rendered with.
+	Image  string    `json:"image"`
+	Hidden ThemeCell `json:"hidden"`
+	Number ThemeCell `json:"number"`
+	// Optional cells for the numbers 0 to 8, falling back to the number cell labeled with the number.
+	Numbers   []ThemeCell `json:"numbers"`
+	Bomb      ThemeCell   `json:"bomb"`
+	Flag      ThemeCell   `json:"flag"`
+	StartHere ThemeCell   `json:"startHere"`
+}
+
+var Themes = make(map[string]Theme)
+var DefaultTheme = "classic"
+
+var buttonStyles = map[string]discordgo.ButtonStyle{
+	"primary":   discordgo.PrimaryButton,
+	"secondary": discordgo.SecondaryButton,
+	"success":   discordgo.SuccessButton,
+	"danger":    discordgo.DangerButton,
+}
+
+// Applies the cell to a board button.
+func (c ThemeCell) apply(button *discordgo.Button) {
+	if style, ok := buttonStyles[c.Style]; ok {
+		button.Style = style
+	}
+	button.Emoji = c.Emoji
+	button.Label = c.Label
+}
+
+// Gets the cell as it is written in a message, used to refer to it in text.
+func (c ThemeCell) mention() string {
+	switch {
+	case c.Emoji.ID != "":
+		return fmt.Sprintf("<:%s:%s>", c.Emoji.Name, c.Emoji.ID)
+	case c.Emoji.Name != "":
+		return c.Emoji.Name
+	}
+
+	return fmt.Sprintf("**%s**", c.Label)
+}
+
+// Gets the cell of a revealed spot with the given amount of nearby bombs.
+func (t Theme) numberCell(nearbyBombs int) ThemeCell {
+	cell := t.Number
+	if cell.Label == "" && cell.Emoji.Name == "" {
+		cell.Label = strconv.Itoa(nearbyBombs)
+	}
+	if nearbyBombs >= len(t.Numbers) {
+		return cell
+	}
+
+	override := t.Numbers[nearbyBombs]
+	if override.Style == "" {
+		override.Style = cell.Style
+	}
+
+	return override
+}
+
+func (t Theme) imageTheme() boardimage.Theme {
+	if theme, ok := boardimage.Themes[t.Image]; ok {
+		return theme
+	}
+
+	return BoardImageTheme
+}
+
+func (t Theme) validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("theme without a name")
+	}
+
+	cells := map[string]ThemeCell{
+		"hidden":    t.Hidden,
+		"bomb":      t.Bomb,
+		"flag":      t.Flag,
+		"startHere": t.StartHere,
+	}
+	for index, cell := range t.Numbers {
+		cells[fmt.Sprintf("numbers[%d]", index)] = cell
+	}
+
+	for name, cell := range cells {
+		// Discord refuses buttons that have neither an emoji nor a label.
+		if cell.Label == "" && cell.Emoji.Name == "" {
+			return fmt.Errorf("theme %s: %s needs an emoji or a label", t.Name, name)
+		}
+		if _, ok := buttonStyles[cell.Style]; cell.Style != "" && !ok {
+			return fmt.Errorf("theme %s: %s has unknown style %s", t.Name, name, cell.Style)
+		}
+	}
+	if _, ok := buttonStyles[t.Number.Style]; t.Number.Style != "" && !ok {
+		return fmt.Errorf("theme %s: number has unknown style %s", t.Name, t.Number.Style)
+	}
+
+	return nil
+}
+
+func parseThemes(data []byte) ([]Theme, error) {
+	var themes []Theme
+	if err := json.Unmarshal(data, &themes); err != nil {
+		return nil, err
+	}
+
+	for _, theme := range themes {
+		if err := theme.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return themes, nil
+}
+
+// Loads the built in themes and the ones from THEMES_FILE, then sets the default theme.
+func loadThemes() {
+	themes, err := parseThemes(defaultThemes)
+	if err != nil {
+		panic(err)
+	}
+
+	if path := os.Getenv("THEMES_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("Failed to read themes file\n%v\n", err)
+		} else if custom, err := parseThemes(data); err != nil {
+			fmt.Printf("Failed to load themes file\n%v\n", err)
+		} else {
+			themes = append(themes, custom...)
+		}
+	}
+
+	for _, theme := range themes {
+		Themes[strings.ToLower(theme.Name)] = theme
+	}
+
+	if name := strings.ToLower(os.Getenv("DEFAULT_THEME")); name != "" {
+		if _, ok := Themes[name]; ok {
+			DefaultTheme = name
+		} else {
+			fmt.Printf("Unknown default theme %s, using %s\n", name, DefaultTheme)
+		}
+	}
+
+	setThemeChoices()
+	fmt.Printf("Loaded %d themes\n", len(Themes))
+}
+
+func getTheme(name string) Theme {
+	if theme, ok := Themes[name]; ok {
+		return theme
+	}
+
+	return Themes[DefaultTheme]
+}
+
+// Fills the choices of every command option named theme with the loaded themes.
+func setThemeChoices() {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, name := range names {
+		// Discord allows at most 25 choices.
+		if len(choices) >= 25 {
+			break
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  Themes[name].Name,
+			Value: name,
+		})
+	}
+
+	var walk func(options []*discordgo.ApplicationCommandOption)
+	walk = func(options []*discordgo.ApplicationCommandOption) {
+		for _, option := range options {
+			if option.Name == "theme" && option.Type == discordgo.ApplicationCommandOptionString {
+				option.Choices = choices
+			}
+			walk(option.Options)
+		}
+	}
+	for _, command := range Commands {
+		walk(command.Options)
+	}
+}
+
+// Gets the theme a new game started by the interaction is played with, either picked
+// for the game itself, set in the settings of the user or the default of the guild.
+// Themes sold in the shop are skipped unless the user owns them.
+func gameTheme(i *discordgo.InteractionCreate, userData UserData) string {
+	if i.Type == discordgo.InteractionApplicationCommand {
+		if theme, ok := mapOptions(i.ApplicationCommandData().Options)["theme"]; ok && canUseTheme(userData, theme.StringValue()) {
+			return theme.StringValue()
+		}
+	}
+	if _, ok := Themes[userData.Settings.Theme]; ok && canUseTheme(userData, userData.Settings.Theme) {
+		return userData.Settings.Theme
+	}
+	if guildTheme := getGuildConfig(i.GuildID).DefaultTheme; guildTheme != "" {
+		// Themes sold in the shop stay with the users that bought them, even as the guild default.
+		if _, ok := Themes[guildTheme]; ok && canUseTheme(userData, guildTheme) {
+			return guildTheme
+		}
+	}
+
+	return DefaultTheme
+}
@@ -0,0 +1,244 @@
+package boardimage
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"main/minesweeper"
+	"math"
+	"strconv"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+type Theme struct {
+	Name       string
+	TileSize   int
+	Gap        int
+	Background color.RGBA
+	Hidden     color.RGBA
+	Revealed   color.RGBA
+	Exploded   color.RGBA
+	Flagged    color.RGBA
+	StartHere  color.RGBA
+	Bomb       color.RGBA
+	Flag       color.RGBA
+	// Colors of the numbers 1 to 8, zeros are left empty.
+	Numbers [8]color.RGBA
+}
+
+type Options struct {
+	Theme Theme
+	// Draw the actual spot types instead of what the player has uncovered.
+	Reveal bool
+	// Draw every bomb as a flag, for boards that have been won.
+	Won bool
+}
+
+var classicNumbers = [8]color.RGBA{
+	{0x00, 0x00, 0xff, 0xff},
+	{0x00, 0x80, 0x00, 0xff},
+	{0xff, 0x00, 0x00, 0xff},
+	{0x00, 0x00, 0x80, 0xff},
+	{0x80, 0x00, 0x00, 0xff},
+	{0x00, 0x80, 0x80, 0xff},
+	{0x00, 0x00, 0x00, 0xff},
+	{0x80, 0x80, 0x80, 0xff},
+}
+
+var Classic = Theme{
+	Name:       "classic",
+	TileSize:   48,
+	Gap:        4,
+	Background: color.RGBA{0x7b, 0x7b, 0x7b, 0xff},
+	Hidden:     color.RGBA{0xbd, 0xbd, 0xbd, 0xff},
+	Revealed:   color.RGBA{0xe0, 0xe0, 0xe0, 0xff},
+	Exploded:   color.RGBA{0xff, 0x40, 0x40, 0xff},
+	Flagged:    color.RGBA{0xbd, 0xbd, 0xbd, 0xff},
+	StartHere:  color.RGBA{0x7c, 0xd9, 0x7c, 0xff},
+	Bomb:       color.RGBA{0x10, 0x10, 0x10, 0xff},
+	Flag:       color.RGBA{0xe0, 0x10, 0x10, 0xff},
+	Numbers:    classicNumbers,
+}
+
+var Discord = Theme{
+	Name:       "discord",
+	TileSize:   48,
+	Gap:        6,
+	Background: color.RGBA{0x31, 0x33, 0x38, 0xff},
+	Hidden:     color.RGBA{0x58, 0x65, 0xf2, 0xff},
+	Revealed:   color.RGBA{0x4e, 0x50, 0x58, 0xff},
+	Exploded:   color.RGBA{0xda, 0x37, 0x3c, 0xff},
+	Flagged:    color.RGBA{0x24, 0x80, 0x46, 0xff},
+	StartHere:  color.RGBA{0xfe, 0xe7, 0x5c, 0xff},
+	Bomb:       color.RGBA{0x11, 0x12, 0x14, 0xff},
+	Flag:       color.RGBA{0xf2, 0x3f, 0x43, 0xff},
+	Numbers: [8]color.RGBA{
+		{0x8e, 0xa1, 0xe1, 0xff},
+		{0x57, 0xf2, 0x87, 0xff},
+		{0xf2, 0x3f, 0x43, 0xff},
+		{0xeb, 0x45, 0x9e, 0xff},
+		{0xfe, 0xe7, 0x5c, 0xff},
+		{0x3b, 0xa5, 0x5d, 0xff},
+		{0xff, 0xff, 0xff, 0xff},
+		{0xb5, 0xba, 0xc1, 0xff},
+	},
+}
+
+// Built in themes by name.
+var Themes = map[string]Theme{
+	Classic.Name: Classic,
+	Discord.Name: Discord,
+}
+
+var (
+	numberFont     *opentype.Font
+	numberFontErr  error
+	numberFontOnce sync.Once
+)
+
+// Creates the font face numbers are drawn with on tiles of the given size. Faces can't be used
+// by several renders at once, so every render gets its own and only the parsed font is shared.
+func numberFace(tileSize int) (font.Face, error) {
+	numberFontOnce.Do(func() {
+		numberFont, numberFontErr = opentype.Parse(gobold.TTF)
+	})
+	if numberFontErr != nil {
+		return nil, numberFontErr
+	}
+
+	return opentype.NewFace(numberFont, &opentype.FaceOptions{
+		Size:    float64(tileSize) * 0.6,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
+
+// Renders a board of any size as an image.
+func Render(game *minesweeper.Game, opts Options) (*image.RGBA, error) {
+	theme := opts.Theme
+	if theme.TileSize <= 0 {
+		theme = Classic
+	}
+
+	face, err := numberFace(theme.TileSize)
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	columns, rows := game.Size()
+	step := theme.TileSize + theme.Gap
+	img := image.NewRGBA(image.Rect(0, 0, columns*step+theme.Gap, rows*step+theme.Gap))
+	fill(img, img.Bounds(), theme.Background)
+
+	for _, spot := range game.Spots {
+		tile := image.Rect(0, 0, theme.TileSize, theme.TileSize).Add(image.Pt(theme.Gap+spot.X*step, theme.Gap+spot.Y*step))
+
+		switch spot.ShownType(opts.Reveal, opts.Won) {
+		case minesweeper.Hidden:
+			fill(img, tile, theme.Hidden)
+
+		case minesweeper.Normal:
+			fill(img, tile, theme.Revealed)
+			if spot.NearbyBombs > 0 && spot.NearbyBombs <= len(theme.Numbers) {
+				drawNumber(img, tile, face, spot.NearbyBombs, theme.Numbers[spot.NearbyBombs-1])
+			}
+
+		case minesweeper.Bomb:
+			fill(img, tile, theme.Exploded)
+			drawBomb(img, tile, theme.Bomb)
+
+		case minesweeper.Flag:
+			fill(img, tile, theme.Flagged)
+			drawFlag(img, tile, theme.Flag, theme.Bomb)
+
+		case minesweeper.StartHere:
+			fill(img, tile, theme.StartHere)
+		}
+	}
+
+	return img, nil
+}
+
+// Renders a board and writes it as a PNG.
+func Encode(w io.Writer, game *minesweeper.Game, opts Options) error {
+	img, err := Render(game, opts)
+	if err != nil {
+		return err
+	}
+
+	return png.Encode(w, img)
+}
+
+func fill(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+func drawNumber(img draw.Image, tile image.Rectangle, face font.Face, number int, c color.Color) {
+	text := strconv.Itoa(number)
+	bounds, _ := font.BoundString(face, text)
+	width := (bounds.Max.X - bounds.Min.X).Ceil()
+	height := (bounds.Max.Y - bounds.Min.Y).Ceil()
+
+	drawer := &font.Drawer{
+		Dst:  img,
+		Src:  &image.Uniform{c},
+		Face: face,
+		Dot: fixed.Point26_6{
+			X: fixed.I(tile.Min.X+(tile.Dx()-width)/2) - bounds.Min.X,
+			Y: fixed.I(tile.Min.Y+(tile.Dy()-height)/2) - bounds.Min.Y,
+		},
+	}
+	drawer.DrawString(text)
+}
+
+// Fills every pixel of the tile the inside function reports, with coordinates relative to the tile center scaled to -1..1.
+func fillShape(img draw.Image, tile image.Rectangle, c color.Color, inside func(x, y float64) bool) {
+	half := float64(tile.Dx()) / 2
+	for py := tile.Min.Y; py < tile.Max.Y; py++ {
+		for px := tile.Min.X; px < tile.Max.X; px++ {
+			x := (float64(px-tile.Min.X) + 0.5 - half) / half
+			y := (float64(py-tile.Min.Y) + 0.5 - half) / half
+			if inside(x, y) {
+				img.Set(px, py, c)
+			}
+		}
+	}
+}
+
+func drawBomb(img draw.Image, tile image.Rectangle, c color.Color) {
+	fillShape(img, tile, c, func(x, y float64) bool {
+		if x*x+y*y <= 0.4*0.4 {
+			return true
+		}
+		// Spikes along both axes and diagonals.
+		ax, ay := math.Abs(x), math.Abs(y)
+		spike := 0.07
+		if ax+ay <= 0.85 && math.Abs(ax-ay) <= spike*1.4 {
+			return true
+		}
+		return (ax <= spike && ay <= 0.65) || (ay <= spike && ax <= 0.65)
+	})
+}
+
+func drawFlag(img draw.Image, tile image.Rectangle, flag, pole color.Color) {
+	fillShape(img, tile, pole, func(x, y float64) bool {
+		pole := x >= 0.05 && x <= 0.15 && y >= -0.6 && y <= 0.45
+		base := y >= 0.45 && y <= 0.6 && x >= -0.35 && x <= 0.45
+		return pole || base
+	})
+	fillShape(img, tile, flag, func(x, y float64) bool {
+		// Triangle pointing left from the top of the pole.
+		if x > 0.05 || y < -0.6 || y > 0.05 {
+			return false
+		}
+		return -x <= 0.55*(1-math.Abs(y+0.275)/0.325)
+	})
+}
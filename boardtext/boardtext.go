@@ -0,0 +1,105 @@
+package boardtext
+
+import (
+	"main/minesweeper"
+	"strings"
+)
+
+// Board styles selectable by users.
+const (
+	StyleButtons = "buttons"
+	StyleSpoiler = "spoiler"
+)
+
+type Options struct {
+	// Draw the actual spot types instead of what the player has uncovered.
+	Reveal bool
+	// Draw every bomb as a flag, for boards that have been won.
+	Won bool
+}
+
+type Symbols struct {
+	Hidden    string
+	Bomb      string
+	Flag      string
+	StartHere string
+	// Symbols of the numbers 0 to 8.
+	Numbers [9]string
+}
+
+var EmojiSymbols = Symbols{
+	Hidden:    "🟦",
+	Bomb:      "💥",
+	Flag:      "🚩",
+	StartHere: "🟨",
+	Numbers:   [9]string{"0️⃣", "1️⃣", "2️⃣", "3️⃣", "4️⃣", "5️⃣", "6️⃣", "7️⃣", "8️⃣"},
+}
+
+var TextSymbols = Symbols{
+	Hidden:    "#",
+	Bomb:      "*",
+	Flag:      "F",
+	StartHere: "S",
+	Numbers:   [9]string{".", "1", "2", "3", "4", "5", "6", "7", "8"},
+}
+
+// Renders the board row by row with the given symbols, joining the spots of a row with the separator.
+func Render(game *minesweeper.Game, symbols Symbols, separator string, opts Options) string {
+	columns, rows := game.Size()
+	lines := make([]string, 0, rows)
+	for y := 0; y < rows; y++ {
+		row := make([]string, 0, columns)
+		for x := 0; x < columns; x++ {
+			row = append(row, symbol(game.FindSpot(x, y), symbols, opts))
+		}
+		lines = append(lines, strings.Join(row, separator))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func symbol(spot *minesweeper.Spot, symbols Symbols, opts Options) string {
+	switch spot.ShownType(opts.Reveal, opts.Won) {
+	case minesweeper.Normal:
+		return symbols.Numbers[spot.NearbyBombs]
+	case minesweeper.Bomb:
+		return symbols.Bomb
+	case minesweeper.Flag:
+		return symbols.Flag
+	case minesweeper.StartHere:
+		return symbols.StartHere
+	}
+
+	return symbols.Hidden
+}
+
+// Renders the board as emojis.
+func Emoji(game *minesweeper.Game, opts Options) string {
+	return Render(game, EmojiSymbols, "", opts)
+}
+
+// Renders the board as monospace text inside a code block.
+func Text(game *minesweeper.Game, opts Options) string {
+	return "```\n" + Render(game, TextSymbols, " ", opts) + "\n```"
+}
+
+// Renders the board in the spoiler format, every spot is hidden behind a spoiler tag and is
+// uncovered by clicking it. The start spot, if any, is left uncovered.
+func Spoiler(game *minesweeper.Game) string {
+	columns, rows := game.Size()
+	lines := make([]string, 0, rows)
+	for y := 0; y < rows; y++ {
+		var row strings.Builder
+		for x := 0; x < columns; x++ {
+			spot := game.FindSpot(x, y)
+			if spot.DisplayedType == minesweeper.StartHere {
+				row.WriteString(EmojiSymbols.Numbers[spot.NearbyBombs])
+				continue
+			}
+			row.WriteString("||" + symbol(spot, EmojiSymbols, Options{Reveal: true}) + "||")
+		}
+		lines = append(lines, row.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,183 @@
+package humanizetime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanizeDuration(t *testing.T) {
+	tests := []struct {
+		name      string
+		duration  time.Duration
+		precision int
+		want      string
+	}{
+		{"zero", 0, 3, "0 seconds"},
+		{"single second", time.Second, 3, "1 second"},
+		{"fractional seconds", 3512 * time.Millisecond, 3, "3.512 seconds"},
+		{"one and a bit seconds", 1500 * time.Millisecond, 3, "1.5 seconds"},
+		{"minute and seconds", 63512 * time.Millisecond, 3, "1 minute and 3.512 seconds"},
+		{"minutes", 2 * time.Minute, 3, "2 minutes and 0 seconds"},
+		{"hours and minutes", time.Hour + 2*time.Minute + 3*time.Second, 0, "1 hour, 2 minutes and 3 seconds"},
+		{"precision", 1234567 * time.Microsecond, 1, "1.2 seconds"},
+		{"rounds up into minutes", 59999600 * time.Microsecond, 3, "1 minute and 0 seconds"},
+		{"day", 24 * time.Hour, 0, "1 day and 0 seconds"},
+		{"negative", -3 * time.Second, 0, "-3 seconds"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := HumanizeDuration(test.duration, test.precision); got != test.want {
+				t.Errorf("HumanizeDuration(%v, %d) = %q, want %q", test.duration, test.precision, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMonthLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		want     string
+	}{
+		{"twelve months make a year", 12 * time.Duration(timeOrder[2].Seconds) * time.Second, "1 year and 0 seconds"},
+		{"365 days are not yet a year", 365 * 24 * time.Hour, "11 months, 30 days, 4 hours, 39 minutes and 54 seconds"},
+		{"gregorian year", time.Duration(timeOrder[1].Seconds) * time.Second, "1 year and 0 seconds"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := HumanizeDuration(test.duration, 0); got != test.want {
+				t.Errorf("HumanizeDuration(%v, 0) = %q, want %q", test.duration, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		opts     Options
+		want     string
+	}{
+		{"compact", 63512 * time.Millisecond, Options{Style: Compact, Precision: 3}, "1m 03.512s"},
+		{"compact seconds only", 63512 * time.Millisecond, Options{Style: Compact, Largest: "second", Precision: 3}, "63.512s"},
+		{"compact short", 3512 * time.Millisecond, Options{Style: Compact, Precision: 3}, "3.512s"},
+		{"compact hours", time.Hour + 5*time.Minute + 7*time.Second, Options{Style: Compact}, "1h 05m 07s"},
+		{"compact skips empty units", time.Hour + 7*time.Second, Options{Style: Compact}, "1h 07s"},
+		{"largest minute", 2*time.Hour + 30*time.Second, Options{Largest: "minute"}, "120 minutes and 30 seconds"},
+		{"smallest minute", 90 * time.Second, Options{Smallest: "minute", Precision: 1}, "1.5 minutes"},
+		{"smallest hour", 26*time.Hour + 30*time.Minute, Options{Smallest: "hour", Precision: 0}, "1 day and 3 hours"},
+		{"largest below smallest", 90 * time.Second, Options{Largest: "second", Smallest: "minute", Precision: 1}, "1.5 minutes"},
+		{"unknown units fall back", 63 * time.Second, Options{Largest: "fortnight", Smallest: "jiffy"}, "1 minute and 3 seconds"},
+		{"german", 63512 * time.Millisecond, Options{Locale: German, Precision: 3}, "1 Minute und 3,512 Sekunden"},
+		{"german singular", 2*time.Hour + time.Second, Options{Locale: German}, "2 Stunden und 1 Sekunde"},
+		{"german compact", 63512 * time.Millisecond, Options{Locale: German, Style: Compact, Precision: 3}, "1min 03,512s"},
+		{"custom locale", 61 * time.Second, Options{Locale: Locale{
+			Unit:      func(unit string, value float64) string { return unit[:1] },
+			Separator: "; ",
+			And:       " & ",
+		}}, "1 m & 1 s"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Format(test.duration, test.opts); got != test.want {
+				t.Errorf("Format(%v, %+v) = %q, want %q", test.duration, test.opts, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		locale  Locale
+		want    time.Duration
+		wantErr bool
+	}{
+		{"long", "1 minute and 3.512 seconds", English, 63512 * time.Millisecond, false},
+		{"long list", "1 hour, 2 minutes and 3 seconds", English, time.Hour + 2*time.Minute + 3*time.Second, false},
+		{"singular", "1 second", English, time.Second, false},
+		{"compact", "1m 03.512s", English, 63512 * time.Millisecond, false},
+		{"compact seconds", "63.512s", English, 63512 * time.Millisecond, false},
+		{"compact without spaces", "1h2m", English, time.Hour + 2*time.Minute, false},
+		{"case insensitive", "2 Hours AND 1 Minute", English, 2*time.Hour + time.Minute, false},
+		{"months", "2 months", English, 2 * time.Duration(timeOrder[2].Seconds) * time.Second, false},
+		{"negative", "-3s", English, -3 * time.Second, false},
+		{"german", "1 Minute und 3,512 Sekunden", German, 63512 * time.Millisecond, false},
+		{"german compact", "1min 03,512s", German, 63512 * time.Millisecond, false},
+		{"empty", "", English, 0, true},
+		{"only filler", " and ", English, 0, true},
+		{"missing unit", "12", English, 0, true},
+		{"unknown unit", "3 fortnights", English, 0, true},
+		{"garbage", "soon", English, 0, true},
+		{"wrong decimal separator", "3,5 seconds", English, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseIn(test.input, test.locale)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseIn(%q) error = %v, want error %v", test.input, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("ParseIn(%q) = %v, want %v", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	durations := []time.Duration{
+		0,
+		time.Second,
+		63512 * time.Millisecond,
+		time.Hour + 2*time.Minute + 3*time.Second,
+		400 * 24 * time.Hour,
+	}
+	styles := []Options{
+		{Precision: 3},
+		{Style: Compact, Precision: 3},
+		{Style: Compact, Largest: "second", Precision: 3},
+		{Locale: German, Precision: 3},
+		{Locale: German, Style: Compact, Precision: 3},
+	}
+
+	for _, opts := range styles {
+		for _, duration := range durations {
+			formatted := Format(duration, opts)
+			parsed, err := ParseIn(formatted, opts.Locale)
+			if err != nil {
+				t.Errorf("ParseIn(%q) failed: %v", formatted, err)
+				continue
+			}
+			if parsed != duration {
+				t.Errorf("ParseIn(Format(%v)) = %v, formatted as %q", duration, parsed, formatted)
+			}
+		}
+	}
+}
+
+func TestGetLocale(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"en-US", "1 second"},
+		{"de", "1 Sekunde"},
+		{"DE", "1 Sekunde"},
+		{"fr", "1 second"},
+		{"", "1 second"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			if got := Format(time.Second, Options{Locale: GetLocale(test.code)}); got != test.want {
+				t.Errorf("Format with locale %q = %q, want %q", test.code, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package humanizetime
+
+import "strings"
+
+// Words a duration is written with.
+type Locale struct {
+	// Names a unit for the amount of it, units are given by their English singular name.
+	Unit func(unit string, value float64) string
+	// Abbreviations of the units for the compact style, falling back to the English ones.
+	Abbreviations map[string]string
+	Separator     string
+	And           string
+	// Separator of the decimals of a number, a dot if empty.
+	Decimal string
+}
+
+var englishAbbreviations = map[string]string{
+	"decade": "dec",
+	"year":   "y",
+	"month":  "mo",
+	"day":    "d",
+	"hour":   "h",
+	"minute": "m",
+	"second": "s",
+}
+
+var English = Locale{
+	Unit: func(unit string, value float64) string {
+		if value == 1 {
+			return unit
+		}
+		return unit + "s"
+	},
+	Abbreviations: englishAbbreviations,
+	Separator:     ", ",
+	And:           " and ",
+}
+
+var germanUnits = map[string][2]string{
+	"decade": {"Jahrzehnt", "Jahrzehnte"},
+	"year":   {"Jahr", "Jahre"},
+	"month":  {"Monat", "Monate"},
+	"day":    {"Tag", "Tage"},
+	"hour":   {"Stunde", "Stunden"},
+	"minute": {"Minute", "Minuten"},
+	"second": {"Sekunde", "Sekunden"},
+}
+
+var German = Locale{
+	Unit: func(unit string, value float64) string {
+		if value == 1 {
+			return germanUnits[unit][0]
+		}
+		return germanUnits[unit][1]
+	},
+	Abbreviations: map[string]string{
+		"decade": "Jz",
+		"year":   "J",
+		"month":  "Mo",
+		"day":    "T",
+		"hour":   "h",
+		"minute": "min",
+		"second": "s",
+	},
+	Separator: ", ",
+	And:       " und ",
+	Decimal:   ",",
+}
+
+// Built in locales by language code.
+var Locales = map[string]Locale{
+	"en": English,
+	"de": German,
+}
+
+// Gets the built in locale of a language or locale code like en-US, English if there is none.
+func GetLocale(code string) Locale {
+	language, _, _ := strings.Cut(code, "-")
+	if locale, ok := Locales[strings.ToLower(language)]; ok {
+		return locale
+	}
+
+	return English
+}
+
+func (l Locale) abbreviation(unit string) string {
+	if abbreviation, ok := l.Abbreviations[unit]; ok {
+		return abbreviation
+	}
+
+	return englishAbbreviations[unit]
+}
+
+// Replaces the decimal point of a formatted number with the decimal separator of the locale.
+func (l Locale) decimal(number string) string {
+	if l.Decimal == "" {
+		return number
+	}
+
+	return strings.Replace(number, ".", l.Decimal, 1)
+}
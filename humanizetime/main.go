@@ -3,6 +3,8 @@ package humanizetime
 import (
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,20 +13,21 @@ type Unit struct {
 	Seconds    float64
 }
 
-var daysPerMonth = 30.4167
+// Average length of a gregorian year, months being a twelfth of it.
+var daysPerYear = 365.2425
 
 var timeOrder = []Unit{
 	{
 		UnitString: "decade",
-		Seconds:    60 * 60 * 24 * daysPerMonth * 12 * 10,
+		Seconds:    60 * 60 * 24 * daysPerYear * 10,
 	},
 	{
 		UnitString: "year",
-		Seconds:    60 * 60 * 24 * daysPerMonth * 12,
+		Seconds:    60 * 60 * 24 * daysPerYear,
 	},
 	{
 		UnitString: "month",
-		Seconds:    60 * 60 * 24 * daysPerMonth,
+		Seconds:    60 * 60 * 24 * daysPerYear / 12,
 	},
 	{
 		UnitString: "day",
@@ -38,39 +41,108 @@ var timeOrder = []Unit{
 		UnitString: "minute",
 		Seconds:    60,
 	},
+	{
+		UnitString: "second",
+		Seconds:    1,
+	},
+}
+
+type Style int
+
+const (
+	// Units written out in words, "1 minute and 3.512 seconds".
+	Long Style = iota
+	// Units abbreviated, "1m 03.512s".
+	Compact
+)
+
+type Options struct {
+	Style  Style
+	Locale Locale
+	// Largest and smallest unit written, by their English singular name. Time above the largest
+	// unit is counted in it, time below the smallest unit is written as a fraction of it.
+	// Defaults to decades and seconds.
+	Largest  string
+	Smallest string
+	// Amount of decimals of the smallest unit.
+	Precision int
 }
 
 func HumanizeDuration(t time.Duration, secondPrecision int) string {
-	times := make(map[string]float64)
+	return Format(t, Options{Precision: secondPrecision})
+}
+
+// Gets the index of a unit in timeOrder, or the fallback index if there is no such unit.
+func unitIndex(name string, fallback int) int {
+	for index, unit := range timeOrder {
+		if unit.UnitString == name {
+			return index
+		}
+	}
+
+	return fallback
+}
+
+// Writes out the duration as described by the options.
+func Format(t time.Duration, opts Options) string {
+	locale := opts.Locale
+	if locale.Unit == nil {
+		locale = English
+	}
+	largest := unitIndex(opts.Largest, 0)
+	smallest := unitIndex(opts.Smallest, len(timeOrder)-1)
+	if largest > smallest {
+		largest = smallest
+	}
 
-	seconds := t.Seconds()
+	sign := ""
+	if t < 0 {
+		sign = "-"
+		t = -t
+	}
 
-	var timestring string
+	// Round once up front, so a remainder can't round up to a whole smallest unit.
+	ratio := math.Pow(10, float64(opts.Precision))
+	remaining := math.Round(t.Seconds()/timeOrder[smallest].Seconds*ratio) / ratio * timeOrder[smallest].Seconds
 
-	for _, unit := range timeOrder {
-		value := math.Floor(seconds / unit.Seconds)
+	var parts []string
+	for index := largest; index < smallest; index++ {
+		unit := timeOrder[index]
+		value := math.Floor(remaining / unit.Seconds)
 		if value <= 0 {
 			continue
 		}
 
-		unitString := unit.UnitString
-		if value >= 2 {
-			unitString = fmt.Sprintf("%ss", unitString)
-		}
+		remaining -= value * unit.Seconds
+		parts = append(parts, formatPart(locale, opts.Style, unit.UnitString, value, 0, len(parts) > 0))
+	}
 
-		times[unitString] = value
-		seconds -= times[unitString] * unit.Seconds
+	unit := timeOrder[smallest]
+	value := round(remaining/unit.Seconds, opts.Precision)
+	last := formatPart(locale, opts.Style, unit.UnitString, value, opts.Precision, len(parts) > 0)
 
-		timestring = fmt.Sprintf("%s, %.0f %s", timestring, value, unitString)
+	if opts.Style == Compact {
+		return sign + strings.Join(append(parts, last), " ")
 	}
-
-	if len(times) >= 1 {
-		timestring = fmt.Sprintf("%s and ", timestring)[2:]
+	if len(parts) == 0 {
+		return sign + last
 	}
 
-	timestring = fmt.Sprintf("%s%v seconds", timestring, round(seconds, secondPrecision))
+	return sign + strings.Join(parts, locale.Separator) + locale.And + last
+}
+
+// Formats the amount of a single unit. Compact amounts following a larger unit are padded to two
+// digits, like a clock.
+func formatPart(locale Locale, style Style, unit string, value float64, precision int, padded bool) string {
+	if style == Compact {
+		number := strconv.FormatFloat(value, 'f', precision, 64)
+		if padded && value < 10 {
+			number = "0" + number
+		}
+		return locale.decimal(number) + locale.abbreviation(unit)
+	}
 
-	return timestring
+	return fmt.Sprintf("%s %s", locale.decimal(strconv.FormatFloat(value, 'f', -1, 64)), locale.Unit(unit, value))
 }
 
 func round(val float64, precision int) float64 {
@@ -0,0 +1,96 @@
+package humanizetime
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var amountPattern = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)\s*([^\d\s,]+)`)
+
+// Parses a duration written in English by Format, in any style.
+func Parse(s string) (time.Duration, error) {
+	return ParseIn(s, English)
+}
+
+// Parses a duration written in the locale by Format, in any style.
+func ParseIn(s string, locale Locale) (time.Duration, error) {
+	if locale.Unit == nil {
+		locale = English
+	}
+	units := unitNames(locale)
+
+	rest := strings.TrimSpace(s)
+	sign := 1.0
+	if strings.HasPrefix(rest, "-") {
+		sign = -1
+		rest = rest[1:]
+	}
+
+	var seconds float64
+	parsed := false
+	for rest = trimFiller(rest, locale); rest != ""; rest = trimFiller(rest, locale) {
+		match := amountPattern.FindStringSubmatch(rest)
+		if match == nil {
+			return 0, fmt.Errorf("humanizetime: invalid duration %q", s)
+		}
+
+		number := match[1]
+		if locale.Decimal != "" {
+			number = strings.Replace(number, locale.Decimal, ".", 1)
+		}
+		value, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return 0, fmt.Errorf("humanizetime: invalid number %q in %q", match[1], s)
+		}
+
+		unit, ok := units[strings.ToLower(match[2])]
+		if !ok {
+			return 0, fmt.Errorf("humanizetime: unknown unit %q in %q", match[2], s)
+		}
+
+		seconds += value * unit.Seconds
+		parsed = true
+		rest = rest[len(match[0]):]
+	}
+	if !parsed {
+		return 0, fmt.Errorf("humanizetime: invalid duration %q", s)
+	}
+
+	return time.Duration(sign * math.Round(seconds*float64(time.Second))), nil
+}
+
+// Maps every way the locale writes a unit, in lowercase, to the unit.
+func unitNames(locale Locale) map[string]Unit {
+	names := make(map[string]Unit)
+	for _, unit := range timeOrder {
+		// Probe amounts falling in different plural categories across languages.
+		for _, value := range []float64{0, 1, 2, 5, 1.5} {
+			names[strings.ToLower(locale.Unit(unit.UnitString, value))] = unit
+		}
+		names[strings.ToLower(locale.abbreviation(unit.UnitString))] = unit
+	}
+
+	return names
+}
+
+// Trims the whitespace, separators and conjunctions between the amounts of a duration.
+func trimFiller(s string, locale Locale) string {
+	and := strings.ToLower(strings.TrimSpace(locale.And))
+	for {
+		trimmed := strings.TrimLeft(s, " \t\n,")
+		if separator := strings.TrimSpace(locale.Separator); separator != "" {
+			trimmed = strings.TrimPrefix(trimmed, separator)
+		}
+		if and != "" && strings.HasPrefix(strings.ToLower(trimmed), and+" ") {
+			trimmed = trimmed[len(and)+1:]
+		}
+		if trimmed == s {
+			return s
+		}
+		s = trimmed
+	}
+}
@@ -0,0 +1,235 @@
+package localization
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Plural categories, as defined by the Unicode CLDR.
+const (
+	Zero  = "zero"
+	One   = "one"
+	Two   = "two"
+	Few   = "few"
+	Many  = "many"
+	Other = "other"
+)
+
+// A message of a catalog, either a single text, a text per plural category or a list of
+// variants to pick from.
+type Message struct {
+	Text     string
+	Forms    map[string]string
+	Variants []string
+}
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &m.Text); err == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &m.Variants); err == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &m.Forms); err != nil {
+		return fmt.Errorf("message must be a string, a list of strings or plural forms")
+	}
+	if _, ok := m.Forms[Other]; !ok {
+		return fmt.Errorf("plural message without an %q form", Other)
+	}
+
+	return nil
+}
+
+// Catalog holds the messages of every locale, keyed by message ID.
+type Catalog struct {
+	// Locale used for messages missing from the requested locale.
+	Fallback string
+	messages map[string]map[string]Message
+}
+
+func New(fallback string) *Catalog {
+	return &Catalog{
+		Fallback: fallback,
+		messages: make(map[string]map[string]Message),
+	}
+}
+
+// Loads the messages of a locale from JSON, adding to the messages already loaded for it.
+func (c *Catalog) Load(locale string, data []byte) error {
+	var messages map[string]Message
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return fmt.Errorf("locale %s: %w", locale, err)
+	}
+
+	if c.messages[locale] == nil {
+		c.messages[locale] = make(map[string]Message)
+	}
+	for id, message := range messages {
+		c.messages[locale][id] = message
+	}
+
+	return nil
+}
+
+// Gets the loaded locales, sorted.
+func (c *Catalog) Locales() []string {
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	return locales
+}
+
+// Gets the locales a message is looked up in, the locale itself, its language and the fallback.
+func (c *Catalog) chain(locale string) []string {
+	chain := []string{locale}
+	if language, _, ok := strings.Cut(locale, "-"); ok {
+		chain = append(chain, language)
+	}
+
+	return append(chain, c.Fallback)
+}
+
+// Looks up a message, reporting whether any locale has it.
+func (c *Catalog) Lookup(locale, id string) (Message, bool) {
+	for _, candidate := range c.chain(locale) {
+		if message, ok := c.messages[candidate][id]; ok {
+			return message, true
+		}
+	}
+
+	return Message{}, false
+}
+
+// Gets a message formatted with the arguments. Missing messages are returned as their ID.
+func (c *Catalog) Get(locale, id string, args ...interface{}) string {
+	message, ok := c.Lookup(locale, id)
+	if !ok {
+		return id
+	}
+
+	text := message.Text
+	switch {
+	case message.Forms != nil:
+		text = message.Forms[Other]
+	case len(message.Variants) > 0:
+		text = message.Variants[0]
+	}
+
+	return format(text, args)
+}
+
+// Gets the form of a message for the count, formatted with the arguments.
+func (c *Catalog) Plural(locale, id string, count float64, args ...interface{}) string {
+	message, ok := c.Lookup(locale, id)
+	if !ok {
+		return id
+	}
+	if message.Forms == nil {
+		return c.Get(locale, id, args...)
+	}
+
+	text, ok := message.Forms[PluralCategory(locale, count)]
+	if !ok {
+		text = message.Forms[Other]
+	}
+
+	return format(text, args)
+}
+
+// Gets the variants of a message, a message with a single text being its only variant.
+func (c *Catalog) Variants(locale, id string) []string {
+	message, ok := c.Lookup(locale, id)
+	if !ok {
+		return nil
+	}
+	if message.Variants != nil {
+		return message.Variants
+	}
+
+	return []string{c.Get(locale, id)}
+}
+
+// Gets the text of a message in every locale besides the fallback, for APIs that take all
+// translations at once.
+func (c *Catalog) Translations(id string) map[string]string {
+	translations := make(map[string]string)
+	for locale, messages := range c.messages {
+		if locale == c.Fallback {
+			continue
+		}
+		if message, ok := messages[id]; ok && message.Text != "" {
+			translations[locale] = message.Text
+		}
+	}
+
+	return translations
+}
+
+func format(text string, args []interface{}) string {
+	if len(args) == 0 {
+		return text
+	}
+
+	return fmt.Sprintf(text, args...)
+}
+
+// Gets the plural category of a count in the language of the locale. Only the cardinal
+// rules of the most common languages are known, others follow the English rules.
+func PluralCategory(locale string, count float64) string {
+	language, _, _ := strings.Cut(locale, "-")
+	integer := count == float64(int64(count))
+	n := int64(count)
+
+	switch language {
+	case "ja", "ko", "zh", "th", "vi", "id":
+		return Other
+	case "fr", "pt":
+		if count >= 0 && count < 2 {
+			return One
+		}
+		return Other
+	case "ru", "uk", "hr", "sr":
+		if !integer {
+			return Other
+		}
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return One
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return Few
+		}
+		return Many
+	case "pl":
+		if !integer {
+			return Other
+		}
+		switch {
+		case n == 1:
+			return One
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return Few
+		}
+		return Many
+	case "cs":
+		switch {
+		case !integer:
+			return Many
+		case n == 1:
+			return One
+		case n >= 2 && n <= 4:
+			return Few
+		}
+		return Other
+	}
+
+	if integer && n == 1 {
+		return One
+	}
+
+	return Other
+}
@@ -282,3 +282,65 @@ func generateSpots(diff, customBombCount int, allowSurroundingBombs, noStartPosi
 func getKey(x, y int) string {
 	return fmt.Sprintf("%d,%d", x, y)
 }
+
+// Clone creates an independent copy of the game, used to give multiple players the same board.
+func (g *Game) Clone() *Game {
+	clone := &Game{
+		Spots:            make(map[string]*Spot, len(g.Spots)),
+		VisitedZeros:     make(map[string]bool, len(g.VisitedZeros)),
+		Difficulty:       g.Difficulty,
+		SpotsLeft:        g.SpotsLeft,
+		TotalBombs:       g.TotalBombs,
+		HasStartPosition: g.HasStartPosition,
+	}
+
+	for key, spot := range g.Spots {
+		newSpot := *spot
+		newSpot.SurroundingSpots = make([]*Spot, 0, len(spot.SurroundingSpots))
+		clone.Spots[key] = &newSpot
+	}
+
+	// Point the surrounding spots to the copies instead of the original spots.
+	for key, spot := range g.Spots {
+		for _, surroundingSpot := range spot.SurroundingSpots {
+			clone.Spots[key].SurroundingSpots = append(clone.Spots[key].SurroundingSpots, clone.FindSpot(surroundingSpot.X, surroundingSpot.Y))
+		}
+	}
+
+	for key, visited := range g.VisitedZeros {
+		clone.VisitedZeros[key] = visited
+	}
+
+	return clone
+}
+
+// ShownType gets the type a spot is shown as. Revealing shows the actual types of a finished board,
+// keeping correctly placed flags, and on a won board every bomb is shown as a flag.
+func (s *Spot) ShownType(reveal, won bool) int {
+	typeToUse := s.DisplayedType
+	if reveal {
+		typeToUse = s.Type
+	}
+	if reveal && s.DisplayedType == Flag && s.Type == Bomb {
+		typeToUse = Flag
+	}
+	if won && s.Type == Bomb {
+		typeToUse = Flag
+	}
+
+	return typeToUse
+}
+
+// Size gets the amount of columns and rows of the board.
+func (g *Game) Size() (columns, rows int) {
+	for _, spot := range g.Spots {
+		if spot.X+1 > columns {
+			columns = spot.X + 1
+		}
+		if spot.Y+1 > rows {
+			rows = spot.Y + 1
+		}
+	}
+
+	return
+}